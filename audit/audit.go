@@ -0,0 +1,243 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-web/config"
+	"golang-web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 事件类型
+const (
+	EventLogin          = "login"           // 登录
+	EventRegister       = "register"        // 注册
+	EventTokenRefresh   = "token_refresh"   // 刷新令牌
+	EventTokenRejected  = "token_rejected"  // 认证中间件拒绝令牌
+	EventSessionRevoke  = "session_revoke"  // 注销会话
+	EventPasswordChange = "password_change" // 修改密码
+	EventPasswordReset  = "password_reset"  // 管理员重置密码
+	EventReauth         = "reauth"          // 敏感操作前重新验证密码
+	EventEmailChange    = "email_change"    // 修改邮箱
+	EventAccountDelete  = "account_delete"  // 用户注销账号
+	EventSetup          = "setup"           // 初始化管理员
+	EventJobTrigger     = "job_trigger"     // 管理员手动触发定时任务
+	EventClientRejected = "client_rejected" // 客户端凭据认证失败
+)
+
+// 事件结果
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event 审计事件
+type Event struct {
+	ID        int64     `json:"id,omitempty"`
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Outcome   string    `json:"outcome"`
+	UserID    int       `json:"user_id,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	RequestID string    `json:"request_id"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// Filter 审计事件查询条件，零值字段不参与过滤
+type Filter struct {
+	UserID int
+	Type   string
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+// Sink 审计事件输出目标
+type Sink interface {
+	Write(ctx context.Context, e *Event) error
+}
+
+// Store 可查询的审计事件存储
+type Store interface {
+	Sink
+	Query(ctx context.Context, f Filter) ([]Event, error)
+}
+
+// Purger 支持按时间清理历史事件的存储
+type Purger interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ErrNotQueryable 未配置可查询的审计存储
+var ErrNotQueryable = errors.New("未配置可查询的审计日志存储")
+
+var (
+	// sinks 当前启用的输出目标
+	sinks []Sink
+	// store 用于查询的存储，优先使用数据库
+	store Store
+
+	// queue 待写入的事件，由后台协程写入各输出目标，队列满时丢弃新事件
+	// 避免未认证的请求（如伪造的令牌）在请求路径上同步写库
+	queue   chan *Event
+	queueMu sync.RWMutex
+	// writerDone 后台写入协程退出时关闭
+	writerDone chan struct{}
+	// dropped 因队列已满丢弃的事件数
+	dropped atomic.Int64
+)
+
+// Init 根据配置初始化审计日志
+func Init(cfg *config.Config) error {
+	sinks, store = nil, nil
+	if !cfg.Audit.Enabled {
+		return nil
+	}
+
+	for _, name := range cfg.Audit.Sinks {
+		switch name {
+		case "database":
+			s := &dbStore{}
+			sinks = append(sinks, s)
+			store = s
+		case "file":
+			s, err := newFileStore(cfg.Audit.File)
+			if err != nil {
+				return err
+			}
+			sinks = append(sinks, s)
+			if store == nil {
+				store = s
+			}
+		default:
+			return fmt.Errorf("不支持的审计日志输出: %s", name)
+		}
+	}
+
+	queueMu.Lock()
+	queue = make(chan *Event, cfg.Audit.QueueSize)
+	writerDone = make(chan struct{})
+	go writeLoop(queue, writerDone)
+	queueMu.Unlock()
+
+	log.Printf("安全审计日志已启用: %v", cfg.Audit.Sinks)
+	return nil
+}
+
+// Close 停止接收事件，等待队列中的事件写完（最多到 ctx 截止）后释放文件句柄
+func Close(ctx context.Context) {
+	queueMu.Lock()
+	q, done := queue, writerDone
+	queue = nil
+	queueMu.Unlock()
+
+	if q != nil {
+		close(q)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			log.Printf("等待审计日志写入超时，剩余 %d 条未写入", len(q))
+			return
+		}
+	}
+
+	for _, s := range sinks {
+		if f, ok := s.(*fileStore); ok {
+			f.Close()
+		}
+	}
+	sinks, store = nil, nil
+}
+
+// Record 记录审计事件，事件放入队列后由后台协程写入，不阻塞请求
+// 队列已满时丢弃事件并记录日志；写入失败只记录日志，不影响业务
+func Record(ctx context.Context, e *Event) {
+	queueMu.RLock()
+	defer queueMu.RUnlock()
+	if queue == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	select {
+	case queue <- e:
+	default:
+		if n := dropped.Add(1); n == 1 || n%1000 == 0 {
+			log.Printf("审计日志队列已满，累计丢弃 %d 条事件（最近一条 %s/%s）", n, e.Type, e.Outcome)
+		}
+	}
+}
+
+// writeLoop 依次把队列中的事件写入各输出目标，队列关闭且写完后退出
+func writeLoop(q <-chan *Event, done chan<- struct{}) {
+	defer close(done)
+	ctx := context.Background()
+	for e := range q {
+		for _, s := range sinks {
+			if err := s.Write(ctx, e); err != nil {
+				log.Printf("写入审计日志失败 (%s/%s): %v", e.Type, e.Outcome, err)
+			}
+		}
+	}
+}
+
+// Query 查询审计事件，按时间倒序返回
+func Query(ctx context.Context, f Filter) ([]Event, error) {
+	if store == nil {
+		return nil, ErrNotQueryable
+	}
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	return store.Query(ctx, f)
+}
+
+// Purge 删除各存储中 before 之前的审计事件，返回删除的条数
+// 文件存储不支持清理，由日志轮转等外部手段处理
+func Purge(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for _, s := range sinks {
+		p, ok := s.(Purger)
+		if !ok {
+			continue
+		}
+		n, err := p.Purge(ctx, before)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// FromGin 根据请求构造审计事件，填充IP、User-Agent和请求ID
+func FromGin(c *gin.Context, eventType, outcome string) *Event {
+	return &Event{
+		Time:      time.Now(),
+		Type:      eventType,
+		Outcome:   outcome,
+		IP:        c.ClientIP(),
+		UserAgent: utils.Truncate(c.Request.UserAgent(), 255),
+		RequestID: c.GetString("request_id"),
+	}
+}
+
+// RecordGin 记录一条来自请求的审计事件
+func RecordGin(c *gin.Context, eventType, outcome string, userID int, username, reason string) {
+	e := FromGin(c, eventType, outcome)
+	e.UserID = userID
+	e.Username = username
+	e.Reason = utils.Truncate(reason, 255)
+	Record(c.Request.Context(), e)
+}
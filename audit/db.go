@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"golang-web/database"
+)
+
+// dbStore 将审计事件写入 t_audit_log 表
+type dbStore struct{}
+
+// Write 写入审计事件
+func (s *dbStore) Write(ctx context.Context, e *Event) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var userID interface{}
+	if e.UserID != 0 {
+		userID = e.UserID
+	}
+
+	query := `INSERT INTO t_audit_log (event_time, event_type, outcome, user_id, username, ip, user_agent, request_id, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	id, err := database.InsertReturningID(ctx, query,
+		database.FormatTime(e.Time), e.Type, e.Outcome, userID, e.Username,
+		e.IP, e.UserAgent, e.RequestID, e.Reason)
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+// Query 查询审计事件
+func (s *dbStore) Query(ctx context.Context, f Filter) ([]Event, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if f.UserID != 0 {
+		conds = append(conds, "user_id = ?")
+		args = append(args, f.UserID)
+	}
+	if f.Type != "" {
+		conds = append(conds, "event_type = ?")
+		args = append(args, f.Type)
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, "event_time >= ?")
+		args = append(args, database.FormatTime(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "event_time <= ?")
+		args = append(args, database.FormatTime(f.To))
+	}
+
+	query := `SELECT id, event_time, event_type, outcome, user_id, username, ip, user_agent, request_id, reason FROM t_audit_log`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY event_time DESC, id DESC LIMIT ?"
+	args = append(args, f.Limit)
+
+	rows, err := database.Reader(ctx).QueryContext(ctx, database.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]Event, 0)
+	for rows.Next() {
+		var (
+			e      Event
+			userID sql.NullInt64
+		)
+		if err := rows.Scan(&e.ID, &e.Time, &e.Type, &e.Outcome, &userID, &e.Username,
+			&e.IP, &e.UserAgent, &e.RequestID, &e.Reason); err != nil {
+			return nil, err
+		}
+		e.UserID = int(userID.Int64)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// Purge 删除 before 之前的审计事件
+// 使用调用方的上下文（定时任务的超时），不受 database.query_timeout 限制
+func (s *dbStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM t_audit_log WHERE event_time < ?`
+	result, err := database.DB.ExecContext(ctx, database.Rebind(query), database.FormatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// fileStore 将审计事件以 JSONL 格式追加写入文件
+type fileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// newFileStore 以追加模式打开审计日志文件
+func newFileStore(path string) (*fileStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("审计日志输出 file 需要配置 audit.file")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("创建审计日志目录失败: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("打开审计日志文件失败: %v", err)
+	}
+	return &fileStore{path: path, file: f}, nil
+}
+
+// Write 追加一行审计事件
+func (s *fileStore) Write(_ context.Context, e *Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(line)
+	return err
+}
+
+// Query 顺序扫描文件查询审计事件
+func (s *fileStore) Query(ctx context.Context, f Filter) ([]Event, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	events := make([]Event, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // 跳过损坏的行
+		}
+		if !matches(&e, f) {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// 按时间倒序，只保留最新的 Limit 条
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.After(events[j].Time)
+	})
+	if len(events) > f.Limit {
+		events = events[:f.Limit]
+	}
+	return events, nil
+}
+
+// Close 关闭文件
+func (s *fileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// matches 判断事件是否满足查询条件
+func matches(e *Event, f Filter) bool {
+	if f.UserID != 0 && e.UserID != f.UserID {
+		return false
+	}
+	if f.Type != "" && e.Type != f.Type {
+		return false
+	}
+	if !f.From.IsZero() && e.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && e.Time.After(f.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"golang-web/config"
+	"golang-web/database"
+	"golang-web/models"
+	"golang-web/security"
+)
+
+// runBootstrapAdmin 创建第一个管理员
+// 用法: golang-web bootstrap-admin -email admin@example.com [-username admin] [-password xxx]
+// 未指定密码时读取环境变量 BOOTSTRAP_ADMIN_PASSWORD，仍为空则随机生成并只打印一次
+func runBootstrapAdmin(args []string) {
+	fs := flag.NewFlagSet("bootstrap-admin", flag.ExitOnError)
+	opts := configFlags(fs)
+	username := fs.String("username", "admin", "管理员用户名")
+	email := fs.String("email", "", "管理员邮箱（必填）")
+	password := fs.String("password", "", "管理员密码，为空时读取 BOOTSTRAP_ADMIN_PASSWORD 或随机生成")
+	fs.Parse(args)
+
+	if *email == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := loadConfig(*opts)
+	if err := database.InitDB(cfg); err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	defer database.CloseDB()
+
+	if *password == "" {
+		*password = os.Getenv("BOOTSTRAP_ADMIN_PASSWORD")
+	}
+	generated := false
+	if *password == "" {
+		p, err := models.GeneratePassword(20)
+		if err != nil {
+			log.Fatalf("生成随机密码失败: %v", err)
+		}
+		*password, generated = p, true
+	}
+
+	// 指定的密码同样需要满足密码安全策略
+	if err := security.NewPolicy(cfg.Password).Validate(*password, *username, *email); err != nil {
+		log.Fatalf("管理员密码不符合要求: %v", err)
+	}
+
+	user, err := models.BootstrapAdmin(context.Background(), *username, *email, *password)
+	if err != nil {
+		log.Fatalf("创建管理员失败: %v", err)
+	}
+
+	fmt.Printf("管理员已创建: id=%d, username=%s\n", user.ID, user.Username)
+	if generated {
+		fmt.Printf("随机生成的密码（只显示这一次，请妥善保存）: %s\n", *password)
+	}
+}
+
+// checkDefaultAdmin 检查早期版本自动创建的 admin/admin123 账号
+// 生产环境拒绝启动，其他环境禁用该账号并替换为随机密码，需重置密码后重新启用
+func checkDefaultAdmin(ctx context.Context, cfg *config.Config) error {
+	user, err := models.FindDefaultAdmin(ctx)
+	if err != nil {
+		return fmt.Errorf("检查默认管理员账号失败: %v", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	if cfg.Env == "production" {
+		return fmt.Errorf("账号 %s 仍在使用默认密码，生产环境拒绝启动，请先执行 golang-web user reset-password %s",
+			user.Username, user.Username)
+	}
+	if err := models.LockDefaultAdmin(ctx, user); err != nil {
+		return fmt.Errorf("禁用默认管理员账号失败: %v", err)
+	}
+	log.Printf("账号 %s 仍在使用默认密码，已禁用并替换为随机密码，请执行 golang-web user reset-password %s 和 golang-web user enable %s 后再使用",
+		user.Username, user.Username, user.Username)
+	return nil
+}
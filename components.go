@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"golang-web/audit"
+	"golang-web/config"
+	"golang-web/database"
+	"golang-web/lifecycle"
+	"golang-web/routes"
+	"golang-web/scheduler"
+	"golang-web/server"
+)
+
+// newScheduler 创建定时任务调度器并注册内置任务
+func newScheduler(cfg *config.Config) (*scheduler.Scheduler, error) {
+	sched := scheduler.New(cfg.Scheduler)
+	if err := scheduler.RegisterMaintenanceJobs(sched); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// databaseComponent 数据库连接（主库和只读副本）
+func databaseComponent(cfg *config.Config) lifecycle.Component {
+	return lifecycle.Func("database",
+		func(ctx context.Context) error {
+			if err := database.InitDB(cfg); err != nil {
+				return err
+			}
+			return checkDefaultAdmin(ctx, cfg)
+		},
+		func(ctx context.Context) error {
+			database.CloseDB()
+			return nil
+		})
+}
+
+// auditComponent 安全审计日志
+func auditComponent(cfg *config.Config) lifecycle.Component {
+	return lifecycle.Func("audit",
+		func(ctx context.Context) error {
+			return audit.Init(cfg)
+		},
+		func(ctx context.Context) error {
+			audit.Close(ctx)
+			return nil
+		})
+}
+
+// configWatcherComponent 配置热加载，监听失败时只记录日志，不影响启动
+func configWatcherComponent(holder *config.Holder) lifecycle.Component {
+	var stop func()
+	return lifecycle.Func("config-watcher",
+		func(ctx context.Context) error {
+			var err error
+			if stop, err = holder.Watch(); err != nil {
+				log.Printf("配置热加载未启用: %v", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			if stop != nil {
+				stop()
+			}
+			return nil
+		})
+}
+
+// httpComponent HTTP 服务器，路由在启动时创建（初始化接口需要查询数据库）
+type httpComponent struct {
+	holder *config.Holder
+	sched  *scheduler.Scheduler
+	srv    *server.Server
+}
+
+func (h *httpComponent) Name() string {
+	return "http"
+}
+
+func (h *httpComponent) Start(ctx context.Context) error {
+	router := routes.SetupRoutes(h.holder, h.sched)
+	srv, err := server.New(h.holder.Get().Server, router)
+	if err != nil {
+		return err
+	}
+	h.srv = srv
+	return srv.Start(ctx)
+}
+
+func (h *httpComponent) Stop(ctx context.Context) error {
+	return h.srv.Stop(ctx)
+}
+
+// Exit 服务异常退出或平滑重启完成时要求应用退出
+func (h *httpComponent) Exit() <-chan error {
+	return h.srv.Exit()
+}
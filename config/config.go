@@ -4,116 +4,418 @@ import (
 	"fmt"
 	"log"
 	"os"
-
-	"github.com/spf13/viper"
+	"time"
 )
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Audit     AuditConfig     `mapstructure:"audit"`
+	Session   SessionConfig   `mapstructure:"session"`
+	Password  PasswordConfig  `mapstructure:"password"`
+	Setup     SetupConfig     `mapstructure:"setup"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Clients   []ClientConfig  `mapstructure:"clients" validate:"dive"`
+
+	Env string `mapstructure:"-"` // 当前运行环境，加载时填充
+
+	options Options  // 加载选项，热加载时按相同选项重新读取
+	sources []string // 实际读取的配置文件
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Network string           `mapstructure:"network" validate:"omitempty,oneof=tcp unix systemd"` // 监听方式: tcp（默认）、unix（Unix 域套接字）、systemd（继承 systemd 套接字激活的监听）
+	Host    string           `mapstructure:"host"`                                                // 监听地址，为空表示所有网卡（tcp）
+	Port    string           `mapstructure:"port" validate:"port"`                                // 监听端口（tcp）
+	Unix    UnixSocketConfig `mapstructure:"unix"`
+	Systemd SystemdConfig    `mapstructure:"systemd"`
+	Mode    string           `mapstructure:"mode" validate:"oneof=debug release test"`
+
+	// 超时，0 表示不限制
+	ReadTimeout       time.Duration `mapstructure:"read_timeout" validate:"gte=0s"`        // 读取整个请求（含请求体）的超时，默认 30s
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" validate:"gte=0s"` // 读取请求头的超时，默认 10s
+	WriteTimeout      time.Duration `mapstructure:"write_timeout" validate:"gte=0s"`       // 写响应的超时，默认 30s
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout" validate:"gte=0s"`        // keep-alive 空闲连接超时，默认 120s
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout" validate:"gte=0s"`    // 优雅关闭等待进行中请求的最长时间，默认 5s
+	UpgradeTimeout    time.Duration `mapstructure:"upgrade_timeout" validate:"gte=0s"`     // 平滑重启时等待新进程就绪的最长时间，默认 30s
+
+	// 大小限制
+	MaxHeaderBytes int   `mapstructure:"max_header_bytes" validate:"gte=0"` // 请求头最大字节数，默认 1MiB
+	MaxBodyBytes   int64 `mapstructure:"max_body_bytes" validate:"gte=0"`   // 请求体最大字节数，超出返回 413，0 表示不限制，默认 4MiB
+
+	TLS ServerTLSConfig `mapstructure:"tls"`
+	H2C bool            `mapstructure:"h2c"` // 未启用 TLS 时支持明文 HTTP/2（h2c），用于内网或反向代理之后
+}
+
+// UnixSocketConfig Unix 域套接字配置（server.network 为 unix 时使用）
+type UnixSocketConfig struct {
+	Path  string `mapstructure:"path"`  // 套接字文件路径
+	Mode  string `mapstructure:"mode"`  // 文件权限（八进制），如 "0660"，默认 0660
+	Group string `mapstructure:"group"` // 文件所属组，如反向代理运行的用户组，为空不修改
+}
+
+// SystemdConfig systemd 套接字激活配置（server.network 为 systemd 时使用）
+type SystemdConfig struct {
+	Name string `mapstructure:"name"` // 使用的监听名称（socket 单元的 FileDescriptorName），为空时使用第一个
+}
+
+// ServerTLSConfig HTTPS 配置
+// 证书和私钥文件变化时自动重新加载，无需重启
+type ServerTLSConfig struct {
+	CertFile     string `mapstructure:"cert_file" validate:"required_with=KeyFile"` // 证书文件（PEM），与 key_file 同时设置时启用 HTTPS
+	KeyFile      string `mapstructure:"key_file" validate:"required_with=CertFile"` // 私钥文件（PEM）
+	MinVersion   string `mapstructure:"min_version" validate:"omitempty,oneof=1.2 1.3"`
+	ClientAuth   string `mapstructure:"client_auth" validate:"omitempty,oneof=none request require verify_if_given require_and_verify"` // 客户端证书认证（mTLS），默认 none
+	ClientCAFile string `mapstructure:"client_ca_file"`                                                                                 // 校验客户端证书的 CA 文件
+}
+
+// Enabled 是否启用 HTTPS
+func (t ServerTLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
 }
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
+	Driver   string `mapstructure:"driver" validate:"omitempty,oneof=mysql postgres"` // mysql 或 postgres，默认 mysql
+	Host     string `mapstructure:"host" validate:"required"`
+	Port     string `mapstructure:"port" validate:"port"`
+	Username string `mapstructure:"username" validate:"required"`
+	Password string `mapstructure:"password" secret:"true"`
+	Database string `mapstructure:"database" validate:"required"`
+
+	QueryTimeout time.Duration `mapstructure:"query_timeout" validate:"gte=0s"` // 单次查询超时时间，如 "5s"，0 表示不限制
+
+	// 连接池
+	MaxOpenConns    int           `mapstructure:"max_open_conns" validate:"gte=0"`      // 最大连接数，默认 25
+	MaxIdleConns    int           `mapstructure:"max_idle_conns" validate:"gte=0"`      // 最大空闲连接数，默认 10
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" validate:"gte=0s"`  // 连接最大生命周期，默认 5m
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time" validate:"gte=0s"` // 连接最大空闲时间，0 表示不限制
+
+	// 连接参数
+	ConnectTimeout time.Duration     `mapstructure:"connect_timeout" validate:"gte=0s"` // 建立连接超时
+	ReadTimeout    time.Duration     `mapstructure:"read_timeout" validate:"gte=0s"`    // 读超时（仅 MySQL）
+	WriteTimeout   time.Duration     `mapstructure:"write_timeout" validate:"gte=0s"`   // 写超时（仅 MySQL）
+	TimeZone       string            `mapstructure:"time_zone"`                         // 时区，如 Local、UTC、Asia/Shanghai，默认 Local；MySQL 按该时区读写时间字段，PostgreSQL 时间字段按 UTC 存储，只设置会话时区
+	TLS            DatabaseTLSConfig `mapstructure:"tls"`
+	Params         map[string]string `mapstructure:"params"` // 额外的DSN参数，优先级最高
+
+	// 启动重试
+	ConnectRetries  int           `mapstructure:"connect_retries" validate:"gte=0"`    // 启动时连接失败的重试次数
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff" validate:"gte=0s"`     // 首次重试间隔，之后按指数增长，默认 1s
+	RetryMaxBackoff time.Duration `mapstructure:"retry_max_backoff" validate:"gte=0s"` // 最大重试间隔，默认 30s
+
+	AutoMigrate bool `mapstructure:"auto_migrate"` // 启动时自动执行未执行的数据库迁移，关闭后需手动执行 migrate up
+
+	// 读写分离：以上配置为主库，Replicas 为只读从库
+	Replicas            []ReplicaConfig `mapstructure:"replicas" validate:"dive"`
+	ReplicaPolicy       string          `mapstructure:"replica_policy"`                          // 从库负载均衡策略: round_robin（默认）、random、least_conn
+	HealthCheckInterval time.Duration   `mapstructure:"health_check_interval" validate:"gte=0s"` // 从库健康检查间隔，默认 10s
+}
+
+// ReplicaConfig 从库配置，未填写的字段沿用主库配置
+type ReplicaConfig struct {
 	Host     string `mapstructure:"host"`
-	Port     string `mapstructure:"port"`
+	Port     string `mapstructure:"port" validate:"omitempty,port"`
 	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
-	Database string `mapstructure:"database"`
+	Password string `mapstructure:"password" secret:"true"`
+}
+
+// DatabaseTLSConfig 数据库TLS配置
+type DatabaseTLSConfig struct {
+	// Mode 取值: disable、preferred、require（加密但不校验证书）、
+	// verify-ca（校验CA）、verify-full（校验CA和主机名），默认 disable
+	// preferred 仅支持 MySQL，lib/pq 不支持 sslmode=prefer，配置校验会拒绝 postgres 与 preferred 的组合
+	Mode       string `mapstructure:"mode" validate:"omitempty,oneof=disable preferred require verify-ca verify-full"`
+	CAFile     string `mapstructure:"ca_file" validate:"required_if=Mode verify-ca"`
+	CertFile   string `mapstructure:"cert_file" validate:"required_with=KeyFile"`
+	KeyFile    string `mapstructure:"key_file" validate:"required_with=CertFile"`
+	ServerName string `mapstructure:"server_name"` // 校验主机名时使用，默认取 host
 }
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	SecretKey string `mapstructure:"secret_key"`
-	Expire    int    `mapstructure:"expire"` // 过期时间（小时）
+	SecretKey    string        `mapstructure:"secret_key" validate:"required,min=16" secret:"true"`
+	Expire       int           `mapstructure:"expire" validate:"gt=0"`          // 过期时间（小时）
+	Issuer       string        `mapstructure:"issuer" validate:"required"`      // 签发者（iss），校验时必须一致，默认 golang-web
+	Audience     string        `mapstructure:"audience" validate:"required"`    // 本服务接口接受的受众（aud），登录签发的令牌默认使用，默认 golang-web
+	Leeway       time.Duration `mapstructure:"leeway" validate:"gte=0s"`        // 校验 exp、nbf、iat 时允许的时钟偏差，默认 30s
+	MaxAge       time.Duration `mapstructure:"max_age" validate:"gte=0s"`       // 令牌从签发（iat）起的最长使用时间，与 exp 无关，0 表示不限制
+	ReauthMaxAge time.Duration `mapstructure:"reauth_max_age" validate:"gt=0s"` // 修改密码、邮箱和注销账号要求最近一次输入密码在该时长之内，默认 5m
+}
+
+// AuditConfig 安全审计日志配置
+type AuditConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Sinks   []string `mapstructure:"sinks" validate:"dive,oneof=database file"` // 输出目标: database（t_audit_log 表）、file（JSONL 文件），可同时配置
+	File    string   `mapstructure:"file"`                                      // JSONL 文件路径
+
+	QueueSize int `mapstructure:"queue_size" validate:"gt=0"` // 待写入事件的队列长度，事件由后台协程写入，队列满时丢弃新事件，默认 1024
+}
+
+// SessionConfig 登录会话配置
+type SessionConfig struct {
+	TouchInterval    time.Duration `mapstructure:"touch_interval" validate:"gte=0s"`    // 最后活跃时间的最小写入间隔，默认 1m
+	AbsoluteLifetime time.Duration `mapstructure:"absolute_lifetime" validate:"gte=0s"` // 从登录起的最长有效期，刷新令牌不能超过，之后必须重新登录，默认 720h，0 表示不限制
+	IdleTimeout      time.Duration `mapstructure:"idle_timeout" validate:"gte=0s"`      // 超过该时长没有请求的会话自动注销，0 表示不限制
+}
+
+// PasswordConfig 密码安全策略配置
+type PasswordConfig struct {
+	MinLength        int    `mapstructure:"min_length" validate:"gte=0"`   // 最小长度，默认 8
+	MaxLength        int    `mapstructure:"max_length" validate:"gte=0"`   // 最大长度（字符数），默认 72；bcrypt 另限 72 字节
+	RequireUpper     bool   `mapstructure:"require_upper"`                 // 必须包含大写字母
+	RequireLower     bool   `mapstructure:"require_lower"`                 // 必须包含小写字母
+	RequireDigit     bool   `mapstructure:"require_digit"`                 // 必须包含数字
+	RequireSymbol    bool   `mapstructure:"require_symbol"`                // 必须包含特殊字符
+	DisallowUserInfo bool   `mapstructure:"disallow_user_info"`            // 不允许包含用户名或邮箱名
+	HistorySize      int    `mapstructure:"history_size" validate:"gte=0"` // 不允许与最近 N 次使用过的密码相同，0 表示不检查
+	BreachedFile     string `mapstructure:"breached_file"`                 // 本地泄露密码库（SHA-1 哈希文件或按前缀分桶的目录），为空表示不检查
+
+	Hash HashConfig `mapstructure:"hash"`
+}
+
+// HashConfig 密码哈希算法配置
+// 登录时会把由其他算法或较弱参数生成的哈希自动升级为当前配置
+type HashConfig struct {
+	Algorithm  string         `mapstructure:"algorithm" validate:"omitempty,oneof=bcrypt argon2id scrypt"` // bcrypt（默认）、argon2id、scrypt
+	BcryptCost int            `mapstructure:"bcrypt_cost" validate:"omitempty,min=4,max=31"`               // bcrypt 成本因子，默认 10
+	Argon2id   Argon2idConfig `mapstructure:"argon2id"`
+	Scrypt     ScryptConfig   `mapstructure:"scrypt"`
+}
+
+// Argon2idConfig argon2id 参数，未配置时使用 m=64MiB, t=3, p=4
+type Argon2idConfig struct {
+	Memory      uint32 `mapstructure:"memory"`      // 内存（KiB）
+	Iterations  uint32 `mapstructure:"iterations"`  // 迭代次数
+	Parallelism uint8  `mapstructure:"parallelism"` // 并行度
+	SaltLength  int    `mapstructure:"salt_length"` // 盐长度（字节）
+	KeyLength   uint32 `mapstructure:"key_length"`  // 输出长度（字节）
+}
+
+// ScryptConfig scrypt 参数，未配置时使用 N=2^15, r=8, p=1
+type ScryptConfig struct {
+	LogN       int `mapstructure:"log_n" validate:"omitempty,min=1,max=30"` // log2(N)
+	R          int `mapstructure:"r"`                                       // 块大小
+	P          int `mapstructure:"p"`                                       // 并行度
+	SaltLength int `mapstructure:"salt_length"`                             // 盐长度（字节）
+	KeyLength  int `mapstructure:"key_length"`                              // 输出长度（字节）
+}
+
+// SetupConfig 首次初始化配置
+type SetupConfig struct {
+	Enabled bool   `mapstructure:"enabled"`                                         // 是否开放一次性初始化接口 POST /api/v1/setup
+	Token   string `mapstructure:"token" validate:"omitempty,min=16" secret:"true"` // 初始化令牌，为空时启动时随机生成并打印到日志
+}
+
+// SchedulerConfig 定时任务配置
+type SchedulerConfig struct {
+	Enabled bool                 `mapstructure:"enabled"`              // 是否按计划执行任务，关闭后仍可通过管理接口手动触发
+	Jobs    map[string]JobConfig `mapstructure:"jobs" validate:"dive"` // 按任务名覆盖任务注册时的默认值
+}
+
+// JobConfig 单个定时任务的配置，未设置的字段使用任务注册时的默认值
+type JobConfig struct {
+	Schedule  string        `mapstructure:"schedule" validate:"omitempty,cron"` // cron 表达式（分 时 日 月 周）或 @hourly、@daily、@every 1h
+	Disabled  bool          `mapstructure:"disabled"`                           // 不按计划执行
+	Timeout   time.Duration `mapstructure:"timeout" validate:"gte=0s"`          // 单次执行的超时时间，同时也是任务锁的租期
+	Retention time.Duration `mapstructure:"retention" validate:"gte=0s"`        // 清理类任务保留数据的时长
+}
+
+// ClientConfig 下游服务的客户端凭据，用于调用令牌内省等服务间接口（HTTP Basic 认证）
+type ClientConfig struct {
+	ID        string   `mapstructure:"id" validate:"required"`
+	Secret    string   `mapstructure:"secret" validate:"required,min=16" secret:"true"`
+	Audiences []string `mapstructure:"audiences" validate:"dive,required"` // 该客户端可以内省的令牌受众，默认只有 jwt.audience
+}
+
+// Client 按ID查找客户端，不存在时返回 nil
+func (c *Config) Client(id string) *ClientConfig {
+	for i := range c.Clients {
+		if c.Clients[i].ID == id {
+			return &c.Clients[i]
+		}
+	}
+	return nil
+}
+
+// ClientAudiences 客户端可以内省的令牌受众，客户端不存在时返回 nil
+func (c *Config) ClientAudiences(id string) []string {
+	client := c.Client(id)
+	if client == nil {
+		return nil
+	}
+	if len(client.Audiences) == 0 {
+		return []string{c.JWT.Audience}
+	}
+	return client.Audiences
+}
+
+// KnownAudiences jwt.audience 和所有客户端配置的受众，去重后按出现顺序排列
+func (c *Config) KnownAudiences() []string {
+	audiences := []string{c.JWT.Audience}
+	for _, client := range c.Clients {
+		for _, aud := range client.Audiences {
+			if !contains(audiences, aud) {
+				audiences = append(audiences, aud)
+			}
+		}
+	}
+	return audiences
+}
+
+// Options 配置加载选项，通常来自命令行参数
+type Options struct {
+	File string // 配置文件路径，指定后只读取该文件，不再查找 config.yaml 等分层配置文件
+	Env  string // 运行环境，优先于环境变量 GO_ENV
 }
 
 // LoadConfig 加载配置文件
 func LoadConfig() *Config {
+	return Load(Options{})
+}
+
+// Load 按选项加载配置，配置无法读取或校验不通过时终止程序
+func Load(opts Options) *Config {
+	config, err := Read(opts)
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	return config
+}
+
+// Read 按选项读取并校验配置
+// 优先级从低到高: 内置默认值、config.yaml、config.<env>.yaml、config.local.yaml、
+// 环境变量（APP_ 前缀，支持 _FILE 后缀读取密钥文件），每一层只覆盖其设置了的配置项
+// 校验不通过时仍返回已解析的配置，便于 config check 输出
+func Read(opts Options) (*Config, error) {
 	// 获取环境变量
-	env := os.Getenv("GO_ENV")
+	env := opts.Env
+	if env == "" {
+		env = os.Getenv("GO_ENV")
+	}
 	if env == "" {
 		env = "development" // 默认使用开发环境
 	}
+	if !profilePattern.MatchString(env) {
+		return nil, fmt.Errorf("无效的运行环境名称: %q", env)
+	}
 
-	// 设置配置文件路径
-	viper.SetConfigName(fmt.Sprintf("config.%s", env))
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./config")
-	viper.AddConfigPath(".")
-
-	// 读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
-		log.Printf("警告: 无法读取配置文件 %s.yaml，使用默认配置", env)
-		// 使用默认配置
-		return getDefaultConfig(env)
+	v, sources, err := newLayeredViper(opts, env)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		log.Printf("警告: 未找到配置文件（config.yaml、config.%s.yaml），仅使用内置默认值和环境变量", env)
 	}
 
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
-		log.Fatalf("解析配置文件失败: %v", err)
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
 	}
+	config.Env = env
+	config.options = Options{File: opts.File, Env: env}
+	config.sources = sources
 
-	return &config
+	return &config, config.Validate()
 }
 
-// getDefaultConfig 获取默认配置
-func getDefaultConfig(env string) *Config {
-	if env == "production" {
-		return &Config{
-			Server: ServerConfig{
-				Port: "8080",
-				Mode: "release",
-			},
-			Database: DatabaseConfig{
-				Host:     "localhost",
-				Port:     "3306",
-				Username: "root",
-				Password: "123456",
-				Database: "golang_web",
-			},
-			JWT: JWTConfig{
-				SecretKey: "your-secret-key-change-in-production",
-				Expire:    24, // 24小时
-			},
-		}
-	}
+// Sources 实际读取的配置文件，按合并顺序排列
+func (c *Config) Sources() []string {
+	return c.sources
+}
 
-	// 开发环境
+// defaults 内置默认值，配置文件和环境变量未设置的配置项使用这里的值
+// 不包含任何口令和密钥，这些必须由配置文件或环境变量提供
+func defaults() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: "8080",
-			Mode: "debug",
+			Network: "tcp",
+			Port:    "8080",
+			Unix: UnixSocketConfig{
+				Mode: "0660",
+			},
+			Mode: "release",
+
+			ReadTimeout:       30 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   5 * time.Second,
+			UpgradeTimeout:    30 * time.Second,
+
+			MaxHeaderBytes: 1 << 20,
+			MaxBodyBytes:   4 << 20,
+
+			TLS: ServerTLSConfig{
+				MinVersion: "1.2",
+				ClientAuth: "none",
+			},
 		},
 		Database: DatabaseConfig{
+			Driver:   "mysql",
 			Host:     "localhost",
 			Port:     "3306",
 			Username: "root",
-			Password: "123456",
-			Database: "golang_dev",
+			Database: "golang_web",
+
+			QueryTimeout: 5 * time.Second,
+
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 5 * time.Minute,
+
+			ConnectTimeout: 5 * time.Second,
+			ReadTimeout:    30 * time.Second,
+			WriteTimeout:   30 * time.Second,
+			TimeZone:       "Local",
+			TLS: DatabaseTLSConfig{
+				Mode: "disable",
+			},
+
+			ConnectRetries:  5,
+			RetryBackoff:    time.Second,
+			RetryMaxBackoff: 30 * time.Second,
+
+			AutoMigrate: true,
+
+			ReplicaPolicy:       "round_robin",
+			HealthCheckInterval: 10 * time.Second,
 		},
 		JWT: JWTConfig{
-			SecretKey: "dev-secret-key",
-			Expire:    24, // 24小时
+			Expire:       24, // 24小时
+			Issuer:       "golang-web",
+			Audience:     "golang-web",
+			Leeway:       30 * time.Second,
+			ReauthMaxAge: 5 * time.Minute,
 		},
-	}
-}
+		Audit: AuditConfig{
+			Enabled: true,
+			Sinks:   []string{"database"},
+			File:    "logs/audit.jsonl",
 
-// GetDSN 获取数据库连接字符串
-func (c *Config) GetDSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		c.Database.Username,
-		c.Database.Password,
-		c.Database.Host,
-		c.Database.Port,
-		c.Database.Database,
-	)
+			QueueSize: 1024,
+		},
+		Session: SessionConfig{
+			TouchInterval:    time.Minute,
+			AbsoluteLifetime: 720 * time.Hour,
+		},
+		Password: PasswordConfig{
+			MinLength:        8,
+			MaxLength:        72,
+			RequireLower:     true,
+			RequireDigit:     true,
+			DisallowUserInfo: true,
+			HistorySize:      3,
+			Hash: HashConfig{
+				Algorithm:  "bcrypt",
+				BcryptCost: 10,
+			},
+		},
+		Scheduler: SchedulerConfig{
+			Enabled: true,
+		},
+	}
 }
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MySQLTLSConfigName 注册到 MySQL 驱动的自定义TLS配置名
+const MySQLTLSConfigName = "golang-web"
+
+// GetDSN 获取数据库连接字符串
+func (c *Config) GetDSN() string {
+	return c.Database.DSN()
+}
+
+// DSN 获取数据库连接字符串
+func (d DatabaseConfig) DSN() string {
+	if d.Driver == "postgres" {
+		return d.postgresDSN()
+	}
+	return d.mysqlDSN()
+}
+
+// ForReplica 基于主库配置生成从库的连接配置
+func (d DatabaseConfig) ForReplica(r ReplicaConfig) DatabaseConfig {
+	replica := d
+	replica.Replicas = nil
+	if r.Host != "" {
+		replica.Host = r.Host
+	}
+	if r.Port != "" {
+		replica.Port = r.Port
+	}
+	if r.Username != "" {
+		replica.Username = r.Username
+	}
+	if r.Password != "" {
+		replica.Password = r.Password
+	}
+	return replica
+}
+
+// mysqlDSN 获取MySQL连接字符串
+func (d DatabaseConfig) mysqlDSN() string {
+	q := url.Values{}
+	q.Set("charset", "utf8mb4")
+	q.Set("parseTime", "True")
+	q.Set("loc", "Local")
+	// 按匹配行数而非实际修改行数返回 RowsAffected，与 PostgreSQL 行为一致
+	q.Set("clientFoundRows", "true")
+	if d.TimeZone != "" {
+		q.Set("loc", d.TimeZone)
+	}
+	if d.ConnectTimeout > 0 {
+		q.Set("timeout", d.ConnectTimeout.String())
+	}
+	if d.ReadTimeout > 0 {
+		q.Set("readTimeout", d.ReadTimeout.String())
+	}
+	if d.WriteTimeout > 0 {
+		q.Set("writeTimeout", d.WriteTimeout.String())
+	}
+	switch d.TLS.Mode {
+	case "", "disable":
+	case "preferred":
+		q.Set("tls", "preferred")
+	default:
+		// require / verify-ca / verify-full 使用 database 包注册的自定义TLS配置
+		q.Set("tls", MySQLTLSConfigName)
+	}
+	for k, v := range d.Params {
+		q.Set(k, v)
+	}
+
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s",
+		d.Username,
+		d.Password,
+		net.JoinHostPort(d.Host, d.Port),
+		d.Database,
+		q.Encode(),
+	)
+}
+
+// postgresDSN 获取PostgreSQL连接字符串
+func (d DatabaseConfig) postgresDSN() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(d.Username, d.Password),
+		Host:   net.JoinHostPort(d.Host, d.Port),
+		Path:   "/" + d.Database,
+	}
+
+	q := url.Values{}
+	switch d.TLS.Mode {
+	case "", "disable":
+		q.Set("sslmode", "disable")
+	default:
+		// preferred 已在配置校验中拒绝（lib/pq 不支持 sslmode=prefer）
+		q.Set("sslmode", d.TLS.Mode)
+	}
+	if d.TLS.CAFile != "" {
+		q.Set("sslrootcert", d.TLS.CAFile)
+	}
+	if d.TLS.CertFile != "" {
+		q.Set("sslcert", d.TLS.CertFile)
+	}
+	if d.TLS.KeyFile != "" {
+		q.Set("sslkey", d.TLS.KeyFile)
+	}
+	if d.ConnectTimeout > 0 {
+		// lib/pq 只接受整数秒，不足一秒按一秒处理
+		seconds := int((d.ConnectTimeout + time.Second - 1) / time.Second)
+		q.Set("connect_timeout", strconv.Itoa(seconds))
+	}
+	if d.TimeZone != "" && d.TimeZone != "Local" {
+		q.Set("timezone", d.TimeZone)
+	}
+	for k, v := range d.Params {
+		q.Set(k, v)
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 环境变量前缀，如 database.password 对应 APP_DATABASE_PASSWORD
+const EnvPrefix = "APP"
+
+// EnvName 获取配置项对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// bindEnv 为每个配置项绑定环境变量，并读取 _FILE 后缀变量指向的密钥文件
+// 如 APP_DATABASE_PASSWORD_FILE=/run/secrets/db_password 会用文件内容覆盖 database.password
+// 同时设置了两者时 _FILE 优先
+func bindEnv(v *viper.Viper) error {
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		name := EnvName(key)
+		if err := v.BindEnv(key, name); err != nil {
+			return err
+		}
+
+		file := os.Getenv(name + "_FILE")
+		if file == "" {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("读取 %s_FILE 指定的文件失败: %v", name, err)
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return nil
+}
+
+// configKeys 列出配置结构中所有可由环境变量覆盖的叶子配置项
+// 结构体切片和 map（如 database.replicas、database.params）只能在配置文件中设置
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, configKeys(field.Type, key)...)
+		case reflect.Map:
+			continue
+		case reflect.Slice:
+			if field.Type.Elem().Kind() == reflect.Struct {
+				continue
+			}
+			keys = append(keys, key)
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// setDefaults 将配置结构的值设置为 viper 默认值
+func setDefaults(v *viper.Viper, cfg *Config) {
+	setStructDefaults(v, reflect.ValueOf(cfg).Elem(), "")
+}
+
+// setStructDefaults 递归设置结构体各字段的默认值
+func setStructDefaults(v *viper.Viper, val reflect.Value, prefix string) {
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			setStructDefaults(v, val.Field(i), key)
+			continue
+		}
+		v.SetDefault(key, val.Field(i).Interface())
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// liveSections 可以在运行时直接生效的配置段
+// 其余配置段（服务器、数据库、审计日志、初始化）在启动时使用，修改后需要重启
+var liveSections = map[string]bool{
+	"jwt":      true,
+	"session":  true,
+	"password": true,
+}
+
+// Holder 当前生效配置的并发安全访问器
+// 请求处理时通过 Get 获取配置快照，热加载时整体原子替换，不会读到修改了一半的配置
+type Holder struct {
+	current atomic.Pointer[Config]
+
+	mu        sync.Mutex
+	listeners []func(old, new *Config)
+}
+
+// NewHolder 创建配置访问器
+func NewHolder(cfg *Config) *Holder {
+	h := &Holder{}
+	h.current.Store(cfg)
+	return h
+}
+
+// Get 获取当前生效的配置，返回值只读，不应修改
+func (h *Holder) Get() *Config {
+	return h.current.Load()
+}
+
+// OnChange 注册配置变更回调，在新配置生效后调用
+func (h *Holder) OnChange(fn func(old, new *Config)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.listeners = append(h.listeners, fn)
+}
+
+// Watch 监听配置文件变化并热加载，返回的函数用于停止监听
+// 监听所有配置文件所在的目录，新建 config.local.yaml 等文件同样会触发重新加载
+// 新配置校验不通过时保留当前配置；只有可运行时生效的配置段会被应用，其余修改提示需要重启
+func (h *Holder) Watch() (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("创建配置文件监听失败: %v", err)
+	}
+
+	dirs := map[string]bool{}
+	cfg := h.Get()
+	if cfg.options.File == "" {
+		for _, dir := range searchDirs {
+			dirs[filepath.Clean(dir)] = true
+		}
+	}
+	for _, file := range cfg.sources {
+		dirs[filepath.Dir(file)] = true
+	}
+	for dir := range dirs {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("监听配置目录 %s 失败: %v", dir, err)
+		}
+	}
+
+	go func() {
+		// 编辑器保存文件时通常产生多个事件，合并短时间内的事件只重新加载一次
+		var timer *time.Timer
+		for {
+			select {
+			case e, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !h.relevant(e.Name) {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				name := e.Name
+				timer = time.AfterFunc(reloadDelay, func() { h.reload(name) })
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("配置文件监听错误: %v", err)
+			}
+		}
+	}()
+
+	log.Printf("已启用配置热加载: %s", strings.Join(cfg.sources, ", "))
+	return func() { watcher.Close() }, nil
+}
+
+// reloadDelay 配置文件变化后等待多久重新加载
+const reloadDelay = 200 * time.Millisecond
+
+// relevant 判断变化的文件是否会影响配置
+func (h *Holder) relevant(name string) bool {
+	cfg := h.Get()
+	for _, file := range cfg.sources {
+		if sameFile(file, name) {
+			return true
+		}
+	}
+	if cfg.options.File != "" {
+		return false
+	}
+	base := filepath.Base(name)
+	for _, layer := range layerNames(cfg.options.Env) {
+		if base == layer {
+			return true
+		}
+	}
+	return false
+}
+
+// sameFile 比较两个路径是否指向同一文件
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// reload 重新读取配置并应用可运行时生效的修改
+func (h *Holder) reload(file string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old := h.Get()
+	next, err := Read(old.options)
+	if err != nil {
+		log.Printf("配置热加载失败（%s），继续使用当前配置: %v", file, err)
+		return
+	}
+
+	var applied, pending []string
+	for _, key := range changedKeys(reflect.ValueOf(*old), reflect.ValueOf(*next), "") {
+		if liveSections[strings.SplitN(key, ".", 2)[0]] {
+			applied = append(applied, key)
+		} else {
+			pending = append(pending, key)
+		}
+	}
+	if len(pending) > 0 {
+		log.Printf("以下配置修改需要重启后生效: %s", strings.Join(pending, ", "))
+	}
+	if len(applied) == 0 {
+		return
+	}
+
+	// 只替换可运行时生效的配置段，其余保持启动时的值，使 Get 返回的始终是实际生效的配置
+	effective := *old
+	effective.sources = next.sources
+	live := reflect.ValueOf(&effective).Elem()
+	src := reflect.ValueOf(next).Elem()
+	for i := 0; i < live.NumField(); i++ {
+		if liveSections[live.Type().Field(i).Tag.Get("mapstructure")] {
+			live.Field(i).Set(src.Field(i))
+		}
+	}
+	h.current.Store(&effective)
+	log.Printf("配置已热加载: %s", strings.Join(applied, ", "))
+
+	for _, fn := range h.listeners {
+		fn(old, &effective)
+	}
+}
+
+// changedKeys 比较两份配置，返回取值不同的配置项键名
+func changedKeys(a, b reflect.Value, prefix string) []string {
+	if a.Kind() != reflect.Struct {
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return nil
+		}
+		return []string{prefix}
+	}
+
+	var keys []string
+	for i := 0; i < a.NumField(); i++ {
+		tag := a.Type().Field(i).Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = fmt.Sprintf("%s.%s", prefix, tag)
+		}
+		keys = append(keys, changedKeys(a.Field(i), b.Field(i), key)...)
+	}
+	return keys
+}
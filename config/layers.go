@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/viper"
+)
+
+// searchDirs 配置文件查找目录，按顺序取第一个存在的文件
+var searchDirs = []string{"./config", "."}
+
+// profilePattern 合法的环境名称，如 development、test、staging、production
+var profilePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// maxIncludeDepth include 最大嵌套层数
+const maxIncludeDepth = 8
+
+// layerNames 按优先级从低到高排列的配置文件名
+//   - config.yaml: 所有环境共用的基础配置
+//   - config.<env>.yaml: 环境配置
+//   - config.local.yaml: 本机覆盖，不提交到版本库
+func layerNames(env string) []string {
+	return []string{"config.yaml", fmt.Sprintf("config.%s.yaml", env), "config.local.yaml"}
+}
+
+// findLayerFiles 查找存在的配置文件
+func findLayerFiles(env string) []string {
+	var files []string
+	for _, name := range layerNames(env) {
+		for _, dir := range searchDirs {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				files = append(files, path)
+				break
+			}
+		}
+	}
+	return files
+}
+
+// newLayeredViper 按层合并配置：内置默认值 < 配置文件（逐层覆盖）< 环境变量
+// 指定 opts.File 时只读取该文件，不再查找 config.yaml 等文件
+// 返回实际读取的文件列表（包括 include 的文件），用于热加载监听
+func newLayeredViper(opts Options, env string) (*viper.Viper, []string, error) {
+	v := viper.New()
+	setDefaults(v, defaults())
+
+	var files []string
+	if opts.File != "" {
+		files = []string{opts.File}
+	} else {
+		files = findLayerFiles(env)
+	}
+
+	var sources []string
+	for _, file := range files {
+		read, err := mergeFile(v, file, 0, map[string]bool{})
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, read...)
+	}
+
+	if err := bindEnv(v); err != nil {
+		return nil, nil, fmt.Errorf("读取环境变量配置失败: %v", err)
+	}
+	return v, sources, nil
+}
+
+// mergeFile 将配置文件合并到 v 中
+// 文件可以通过顶层的 include 引入其他文件（相对路径相对于当前文件所在目录），
+// 被引入的文件先合并，当前文件中的配置优先
+func mergeFile(v *viper.Viper, path string, depth int, visiting map[string]bool) ([]string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if visiting[abs] {
+		return nil, fmt.Errorf("配置文件循环引用: %s", path)
+	}
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("配置文件 include 嵌套超过 %d 层: %s", maxIncludeDepth, path)
+	}
+	visiting[abs] = true
+	defer delete(visiting, abs)
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("配置文件不存在: %s", path)
+		}
+		return nil, fmt.Errorf("读取配置文件 %s 失败: %v", path, err)
+	}
+
+	var sources []string
+	for _, include := range file.GetStringSlice("include") {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		read, err := mergeFile(v, include, depth+1, visiting)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, read...)
+	}
+
+	settings := file.AllSettings()
+	delete(settings, "include")
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("合并配置文件 %s 失败: %v", path, err)
+	}
+	return append(sources, path), nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue 脱敏后显示的值
+const redactedValue = "******"
+
+// RedactedYAML 以 YAML 格式输出生效的配置，标记了 secret 标签的字段会被脱敏
+// 未设置的密钥保持为空，便于确认哪些密钥缺失
+func (c *Config) RedactedYAML() ([]byte, error) {
+	node, err := redactNode(reflect.ValueOf(*c), false)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
+
+// redactNode 按字段顺序将配置值转换为 YAML 节点
+func redactNode(v reflect.Value, secret bool) (*yaml.Node, error) {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return scalarNode(d.String())
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("mapstructure")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			value, err := redactNode(v.Field(i), field.Tag.Get("secret") == "true")
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: tag}, value)
+		}
+		return node, nil
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := 0; i < v.Len(); i++ {
+			item, err := redactNode(v.Index(i), secret)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		return node, nil
+	case reflect.Map:
+		// 额外的连接参数中可能包含口令
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			value, err := redactNode(v.MapIndex(k), strings.Contains(strings.ToLower(k.String()), "password"))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k.String()}, value)
+		}
+		return node, nil
+	case reflect.String:
+		if secret && v.String() != "" {
+			return scalarNode(redactedValue)
+		}
+	}
+	return scalarNode(v.Interface())
+}
+
+// scalarNode 将标量值编码为 YAML 节点
+func scalarNode(v interface{}) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
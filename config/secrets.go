@@ -0,0 +1,38 @@
+package config
+
+// knownJWTSecrets 仓库中出现过的默认 JWT 密钥，生产环境禁止使用
+var knownJWTSecrets = map[string]bool{
+	"dev-secret-key":                       true,
+	"dev-secret-key-change-in-production":  true,
+	"your-secret-key-change-in-production": true,
+}
+
+// knownDBPasswords 常见的默认数据库口令，生产环境禁止使用
+var knownDBPasswords = map[string]bool{
+	"":         true,
+	"123456":   true,
+	"root":     true,
+	"password": true,
+}
+
+// minProductionSecretLength 生产环境 JWT 密钥最小长度
+const minProductionSecretLength = 32
+
+// productionSecretProblems 列出生产环境不允许的默认密钥和弱口令
+// 空的 JWT 密钥已由通用校验报告，这里不重复
+func productionSecretProblems(cfg *Config) []string {
+	var problems []string
+
+	switch {
+	case cfg.JWT.SecretKey == "":
+	case knownJWTSecrets[cfg.JWT.SecretKey]:
+		problems = append(problems, "jwt.secret_key 使用了默认值")
+	case len(cfg.JWT.SecretKey) < minProductionSecretLength:
+		problems = append(problems, "jwt.secret_key 长度不能少于 32 个字符")
+	}
+	if knownDBPasswords[cfg.Database.Password] {
+		problems = append(problems, "database.password 为空或使用了默认值")
+	}
+
+	return problems
+}
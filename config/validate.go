@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/robfig/cron/v3"
+)
+
+// ValidationError 配置校验错误，汇总所有不合法的配置项
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "配置不合法:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// newValidator 创建配置校验器，错误信息中使用配置文件中的键名（如 server.port）
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := f.Tag.Get("mapstructure")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterValidation("port", func(fl validator.FieldLevel) bool {
+		port, err := strconv.Atoi(fl.Field().String())
+		return err == nil && port > 0 && port <= 65535
+	})
+	v.RegisterValidation("cron", func(fl validator.FieldLevel) bool {
+		_, err := cron.ParseStandard(fl.Field().String())
+		return err == nil
+	})
+	return v
+}
+
+// Validate 按 validate 标签和跨字段规则校验配置，生产环境还会检查默认密钥，返回包含全部问题的 ValidationError
+func (c *Config) Validate() error {
+	var problems []string
+
+	if err := newValidator().Struct(c); err != nil {
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return err
+		}
+		for _, fe := range errs {
+			problems = append(problems, describe(fe))
+		}
+	}
+
+	// 标签无法表达的跨字段规则
+	if c.Audit.Enabled && contains(c.Audit.Sinks, "file") && c.Audit.File == "" {
+		problems = append(problems, "audit.file 不能为空（audit.sinks 包含 file）")
+	}
+	if c.Password.MaxLength > 0 && c.Password.MaxLength < c.Password.MinLength {
+		problems = append(problems, fmt.Sprintf("password.max_length (%d) 不能小于 password.min_length (%d)",
+			c.Password.MaxLength, c.Password.MinLength))
+	}
+	if c.Server.Network == "unix" && c.Server.Unix.Path == "" {
+		problems = append(problems, "server.network 为 unix 时 server.unix.path 不能为空")
+	}
+	if c.Server.Unix.Mode != "" {
+		if _, err := strconv.ParseUint(c.Server.Unix.Mode, 8, 32); err != nil {
+			problems = append(problems, fmt.Sprintf("server.unix.mode 必须是八进制权限，如 0660，当前为 %q", c.Server.Unix.Mode))
+		}
+	}
+	if (c.Server.TLS.ClientAuth == "verify_if_given" || c.Server.TLS.ClientAuth == "require_and_verify") && c.Server.TLS.ClientCAFile == "" {
+		problems = append(problems, fmt.Sprintf("server.tls.client_auth 为 %s 时 server.tls.client_ca_file 不能为空", c.Server.TLS.ClientAuth))
+	}
+	if c.Server.TLS.ClientAuth != "" && c.Server.TLS.ClientAuth != "none" && !c.Server.TLS.Enabled() {
+		problems = append(problems, "server.tls.client_auth 需要同时配置 server.tls.cert_file 和 server.tls.key_file")
+	}
+	if c.Database.Driver == "postgres" && c.Database.TLS.Mode == "preferred" {
+		problems = append(problems, "database.tls.mode 为 preferred 时仅支持 MySQL，PostgreSQL 请使用 disable、require、verify-ca 或 verify-full")
+	}
+	if c.Database.RetryMaxBackoff > 0 && c.Database.RetryMaxBackoff < c.Database.RetryBackoff {
+		problems = append(problems, "database.retry_max_backoff 不能小于 database.retry_backoff")
+	}
+	if c.Session.IdleTimeout > 0 && c.Session.IdleTimeout < c.Session.TouchInterval {
+		problems = append(problems, "session.idle_timeout 不能小于 session.touch_interval")
+	}
+	seenClients := make(map[string]bool, len(c.Clients))
+	for _, client := range c.Clients {
+		if client.ID != "" && seenClients[client.ID] {
+			problems = append(problems, fmt.Sprintf("clients 中的客户端 ID %q 重复", client.ID))
+		}
+		seenClients[client.ID] = true
+	}
+
+	// 生产环境拒绝使用默认密钥和弱口令
+	if c.Env == "production" {
+		problems = append(problems, productionSecretProblems(c)...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// describe 将单个校验错误转换为可读的说明
+func describe(fe validator.FieldError) string {
+	// 去掉根结构名，得到配置键名，如 Config.server.port -> server.port
+	key := fe.Namespace()
+	if i := strings.Index(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	// 字符串长度类错误不回显取值，避免在日志中泄露密钥
+	value := fe.Value()
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s 不能为空", key)
+	case "required_if":
+		params := strings.Fields(fe.Param())
+		return fmt.Sprintf("%s 为 %s 时 %s 不能为空", siblingKey(key, params[0]), params[1], key)
+	case "required_with":
+		return fmt.Sprintf("设置了 %s 时 %s 不能为空", siblingKey(key, fe.Param()), key)
+	case "port":
+		return fmt.Sprintf("%s 必须是 1-65535 之间的端口号，当前为 %q", key, value)
+	case "cron":
+		return fmt.Sprintf("%s 不是有效的 cron 表达式，当前为 %q", key, value)
+	case "oneof":
+		return fmt.Sprintf("%s 必须是以下之一: %s，当前为 %q", key, strings.ReplaceAll(fe.Param(), " ", ", "), value)
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s 长度不能少于 %s 个字符", key, fe.Param())
+		}
+		return fmt.Sprintf("%s 不能小于 %s，当前为 %v", key, fe.Param(), value)
+	case "max":
+		return fmt.Sprintf("%s 不能大于 %s，当前为 %v", key, fe.Param(), value)
+	case "gt":
+		return fmt.Sprintf("%s 必须大于 %s，当前为 %v", key, fe.Param(), value)
+	case "gte":
+		return fmt.Sprintf("%s 不能为负数，当前为 %v", key, value)
+	default:
+		return fmt.Sprintf("%s 不满足规则 %s", key, fe.Tag())
+	}
+}
+
+// dependentTypes 使用字段依赖规则（required_if、required_with）的配置结构
+var dependentTypes = []reflect.Type{
+	reflect.TypeOf(DatabaseTLSConfig{}),
+	reflect.TypeOf(ServerTLSConfig{}),
+}
+
+// siblingKey 根据字段键名和校验参数中的字段名，得到同级配置项的键名
+func siblingKey(key, field string) string {
+	for _, t := range dependentTypes {
+		if sf, ok := t.FieldByName(field); ok {
+			field = sf.Tag.Get("mapstructure")
+			break
+		}
+	}
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i+1] + field
+	}
+	return field
+}
+
+// contains 判断切片是否包含指定字符串
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
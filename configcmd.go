@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang-web/config"
+)
+
+// configUsage config 命令的用法
+const configUsage = `用法:
+  golang-web config print [-env <环境>] [-config <配置文件>]   输出生效的配置（口令和密钥已脱敏）
+  golang-web config check [-env <环境>] [-config <配置文件>]   输出生效的配置并校验，不合法时以状态码 1 退出
+`
+
+// runConfig 配置相关子命令
+func runConfig(args []string) {
+	if len(args) == 0 || (args[0] != "print" && args[0] != "check") {
+		subcommandUsage(configUsage)
+	}
+
+	action := args[0]
+	fs := flag.NewFlagSet("config "+action, flag.ExitOnError)
+	opts := configFlags(fs)
+	fs.Parse(args[1:])
+
+	cfg, err := config.Read(*opts)
+	if cfg == nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("# 环境: %s\n", cfg.Env)
+	fmt.Printf("# 配置文件: %s\n", strings.Join(cfg.Sources(), ", "))
+
+	out, yamlErr := cfg.RedactedYAML()
+	if yamlErr != nil {
+		fmt.Fprintf(os.Stderr, "输出配置失败: %v\n", yamlErr)
+		os.Exit(1)
+	}
+	os.Stdout.Write(out)
+
+	// print 只提示校验问题，便于查看不合法的配置；check 校验失败时以状态码 1 退出
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if action == "check" {
+			os.Exit(1)
+		}
+		return
+	}
+	if action == "check" {
+		fmt.Fprintln(os.Stderr, "配置校验通过")
+	}
+}
@@ -1,139 +1,185 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"time"
 
 	"golang-web/config"
+	"golang-web/security"
 
 	_ "github.com/go-sql-driver/mysql"
-	"golang.org/x/crypto/bcrypt"
+	_ "github.com/lib/pq"
 )
 
 // DB 全局数据库连接
 var DB *sql.DB
 
-// InitDB 初始化数据库连接
+// queryTimeout 单次查询超时时间
+var queryTimeout time.Duration
+
+// InitDB 初始化数据库连接，database.auto_migrate 开启时执行未执行的迁移
 func InitDB(cfg *config.Config) error {
+	if err := Open(cfg); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if cfg.Database.AutoMigrate {
+		if _, err := MigrateUp(ctx, 0); err != nil {
+			return fmt.Errorf("执行数据库迁移失败: %v", err)
+		}
+		return nil
+	}
+
+	pending, err := PendingMigrations(ctx)
+	if err != nil {
+		return fmt.Errorf("检查数据库迁移失败: %v", err)
+	}
+	if pending > 0 {
+		log.Printf("警告: 有 %d 个未执行的数据库迁移，请执行 migrate up", pending)
+	}
+	return nil
+}
+
+// Open 连接主库和从库，不执行迁移
+func Open(cfg *config.Config) error {
 	var err error
 
+	// 选择数据库方言
+	dialect, err := GetDialect(cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+	current = dialect
+	queryTimeout = cfg.Database.QueryTimeout
+	if err := setLocation(cfg.Database); err != nil {
+		return err
+	}
+
+	// MySQL 需要预先注册自定义TLS配置
+	if dialect.Name() == "mysql" {
+		if err := registerMySQLTLS(cfg.Database); err != nil {
+			return err
+		}
+	}
+
 	// 获取数据库连接字符串
 	dsn := cfg.GetDSN()
 
 	// 连接数据库
-	DB, err = sql.Open("mysql", dsn)
+	DB, err = sql.Open(dialect.DriverName(), dsn)
 	if err != nil {
 		return fmt.Errorf("连接数据库失败: %v", err)
 	}
 
 	// 设置连接池参数
-	DB.SetMaxOpenConns(25)                 // 最大连接数
-	DB.SetMaxIdleConns(10)                 // 最大空闲连接数
-	DB.SetConnMaxLifetime(5 * time.Minute) // 连接最大生命周期
+	configurePool(DB, cfg.Database)
 
-	// 测试数据库连接
-	if err := DB.Ping(); err != nil {
+	// 测试数据库连接，失败时按指数退避重试
+	if err := pingWithRetry(DB, cfg.Database); err != nil {
 		return fmt.Errorf("数据库连接测试失败: %v", err)
 	}
 
-	log.Println("数据库连接成功")
-
-	// 初始化数据库表
-	if err := initTables(); err != nil {
-		return fmt.Errorf("初始化数据库表失败: %v", err)
-	}
+	log.Printf("数据库连接成功 (%s)", dialect.Name())
 
-	return nil
+	// 连接从库
+	return initReplicas(cfg.Database)
 }
 
-// CloseDB 关闭数据库连接
-func CloseDB() {
-	if DB != nil {
-		DB.Close()
-		log.Println("数据库连接已关闭")
+// configurePool 设置连接池参数，未配置的项使用默认值
+func configurePool(db *sql.DB, dbCfg config.DatabaseConfig) {
+	maxOpen := dbCfg.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = 25
 	}
-}
-
-// initTables 初始化数据库表
-func initTables() error {
-	// 创建用户表
-	createUserTable := `
-	CREATE TABLE IF NOT EXISTS t_user (
-	  id int(11) NOT NULL AUTO_INCREMENT COMMENT 'id',
-	  username varchar(100) DEFAULT NULL COMMENT '用户名',
-	  password varchar(255) DEFAULT NULL COMMENT '密码',
-	  email varchar(32) DEFAULT '' COMMENT '邮箱',
-	  create_time datetime DEFAULT NULL COMMENT '创建时间',
-	  update_time datetime DEFAULT NULL COMMENT '更新时间',
-	  PRIMARY KEY (id),
-	  UNIQUE KEY idx_user (username) USING BTREE
-	) ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8mb4 COMMENT='用户表';
-	`
-
-	_, err := DB.Exec(createUserTable)
-	if err != nil {
-		return fmt.Errorf("创建用户表失败: %v", err)
+	maxIdle := dbCfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = 10
 	}
-
-	// 检查是否需要插入默认用户
-	if err := insertDefaultUser(); err != nil {
-		log.Printf("插入默认用户失败: %v", err)
+	lifetime := dbCfg.ConnMaxLifetime
+	if lifetime <= 0 {
+		lifetime = 5 * time.Minute
 	}
 
-	log.Println("数据库表初始化完成")
-	return nil
+	db.SetMaxOpenConns(maxOpen)                  // 最大连接数
+	db.SetMaxIdleConns(maxIdle)                  // 最大空闲连接数
+	db.SetConnMaxLifetime(lifetime)              // 连接最大生命周期
+	db.SetConnMaxIdleTime(dbCfg.ConnMaxIdleTime) // 连接最大空闲时间
 }
 
-// insertDefaultUser 插入默认用户（如果不存在）
-func insertDefaultUser() error {
-	// 检查默认用户是否已存在
-	var count int
-	err := DB.QueryRow("SELECT COUNT(*) FROM t_user WHERE username = ?", "admin").Scan(&count)
-	if err != nil {
-		return err
+// pingWithRetry 测试数据库连接，失败后按指数退避重试
+func pingWithRetry(db *sql.DB, dbCfg config.DatabaseConfig) error {
+	backoff := dbCfg.RetryBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	maxBackoff := dbCfg.RetryMaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
 	}
 
-	if count == 0 {
-		// 插入默认用户，密码为 admin123
-		hashedPassword, err := HashPassword("admin123")
-		if err != nil {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = db.Ping(); err == nil {
+			return nil
+		}
+		if attempt >= dbCfg.ConnectRetries {
 			return err
 		}
 
-		// 获取当前时间
-		currentTime := time.Now().Format("2006-01-02 15:04:05")
+		log.Printf("数据库连接失败，%v 后重试 (%d/%d): %v", backoff, attempt+1, dbCfg.ConnectRetries, err)
+		time.Sleep(backoff)
 
-		_, err = DB.Exec("INSERT INTO t_user (username, password, email, create_time, update_time) VALUES (?, ?, ?, ?, ?)",
-			"admin", hashedPassword, "admin@example.com", currentTime, currentTime)
-		if err != nil {
-			return err
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
 		}
+	}
+}
 
-		log.Println("默认用户已创建: admin/admin123")
+// WithTimeout 为数据库操作附加配置的查询超时
+// 父上下文（如请求上下文）被取消时，查询也会随之取消
+func WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if queryTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, queryTimeout)
+}
 
-	return nil
+// CloseDB 关闭数据库连接
+func CloseDB() {
+	closeReplicas()
+	if DB != nil {
+		DB.Close()
+		log.Println("数据库连接已关闭")
+	}
 }
 
-// HashPassword 使用 bcrypt 对密码进行哈希加密
+// HashPassword 使用配置的算法（见 security.ConfigureHasher）对密码进行哈希
 func HashPassword(password string) (string, error) {
-	// 使用 bcrypt 进行密码哈希，默认成本因子为 12
-	// 成本因子越高，哈希越安全但计算时间越长
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashed, err := security.DefaultHasher().Hash(password)
 	if err != nil {
 		return "", fmt.Errorf("密码哈希失败: %v", err)
 	}
-	return string(hashedBytes), nil
+	return hashed, nil
 }
 
-// CheckPassword 验证密码是否匹配哈希值
+// CheckPassword 验证密码是否匹配哈希值，支持所有已支持算法生成的哈希
 func CheckPassword(password, hashedPassword string) error {
-	// 使用 bcrypt 验证密码
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	ok, err := security.DefaultHasher().Verify(password, hashedPassword)
 	if err != nil {
 		return fmt.Errorf("密码验证失败: %v", err)
 	}
+	if !ok {
+		return fmt.Errorf("密码验证失败: 密码不匹配")
+	}
 	return nil
 }
+
+// NeedsRehash 判断密码哈希是否需要升级为当前配置的算法和参数
+func NeedsRehash(hashedPassword string) bool {
+	return security.DefaultHasher().NeedsRehash(hashedPassword)
+}
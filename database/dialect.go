@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect 数据库方言，屏蔽不同数据库在驱动、占位符和DDL上的差异
+type Dialect interface {
+	// Name 方言名称（与配置中的 driver 一致）
+	Name() string
+	// DriverName database/sql 使用的驱动名
+	DriverName() string
+	// Rebind 将 ? 占位符转换为当前方言的占位符
+	Rebind(query string) string
+	// SupportsReturning 是否支持 INSERT ... RETURNING
+	SupportsReturning() bool
+	// Migrations 按版本排序的数据库迁移
+	Migrations() []Migration
+	// ColumnUpgrades 为迁移前版本创建的表补充的字段（迁移 1 执行）
+	ColumnUpgrades() []ColumnUpgrade
+	// CurrentSchema 获取当前库（schema）名的SQL表达式
+	CurrentSchema() string
+	// MigrationTable 迁移记录表的建表语句
+	MigrationTable() string
+	// LockMigrations 在连接上获取迁移锁，返回释放锁的函数
+	LockMigrations(ctx context.Context, conn *sql.Conn) (func(), error)
+}
+
+// migrationLockName 迁移锁的名称（MySQL GET_LOCK）和键（PostgreSQL advisory lock）
+const (
+	migrationLockName = "golang_web_migrate"
+	migrationLockKey  = 7209318241
+)
+
+// ColumnUpgrade 表字段升级：字段不存在时执行 DDL 补充
+type ColumnUpgrade struct {
+	Table  string
+	Column string
+	DDL    string
+}
+
+// mysqlDialect MySQL方言
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string               { return "mysql" }
+func (mysqlDialect) DriverName() string         { return "mysql" }
+func (mysqlDialect) Rebind(query string) string { return query }
+func (mysqlDialect) SupportsReturning() bool    { return false }
+func (mysqlDialect) CurrentSchema() string      { return "DATABASE()" }
+func (mysqlDialect) Migrations() []Migration    { return mysqlMigrations }
+
+func (mysqlDialect) MigrationTable() string {
+	return `
+	CREATE TABLE IF NOT EXISTS t_schema_migration (
+	  version int NOT NULL COMMENT '迁移版本',
+	  name varchar(100) NOT NULL COMMENT '迁移名称',
+	  applied_at datetime NOT NULL COMMENT '执行时间',
+	  PRIMARY KEY (version)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据库迁移记录';
+	`
+}
+
+// LockMigrations 使用 GET_LOCK，最多等待 60 秒
+func (mysqlDialect) LockMigrations(ctx context.Context, conn *sql.Conn) (func(), error) {
+	var ok sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 60)`, migrationLockName).Scan(&ok); err != nil {
+		return nil, err
+	}
+	if ok.Int64 != 1 {
+		return nil, errors.New("等待其他实例执行迁移超时")
+	}
+	return func() {
+		conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, migrationLockName)
+	}, nil
+}
+
+func (mysqlDialect) ColumnUpgrades() []ColumnUpgrade {
+	return []ColumnUpgrade{
+		{"t_user", "status", `ALTER TABLE t_user ADD COLUMN status varchar(16) NOT NULL DEFAULT 'active' COMMENT '状态: active/disabled/deleted'`},
+		{"t_user", "deleted_at", `ALTER TABLE t_user ADD COLUMN deleted_at datetime DEFAULT NULL COMMENT '删除时间'`},
+		{"t_user", "last_login_at", `ALTER TABLE t_user ADD COLUMN last_login_at datetime DEFAULT NULL COMMENT '最后登录时间'`},
+		{"t_user", "last_login_ip", `ALTER TABLE t_user ADD COLUMN last_login_ip varchar(64) DEFAULT '' COMMENT '最后登录IP'`},
+		{"t_user", "created_by", `ALTER TABLE t_user ADD COLUMN created_by int(11) DEFAULT NULL COMMENT '创建人'`},
+		{"t_user", "updated_by", `ALTER TABLE t_user ADD COLUMN updated_by int(11) DEFAULT NULL COMMENT '更新人'`},
+		{"t_user", "role", `ALTER TABLE t_user ADD COLUMN role varchar(16) NOT NULL DEFAULT 'user' COMMENT '角色: user/admin'`},
+	}
+}
+
+// postgresDialect PostgreSQL方言
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string            { return "postgres" }
+func (postgresDialect) DriverName() string      { return "postgres" }
+func (postgresDialect) SupportsReturning() bool { return true }
+func (postgresDialect) CurrentSchema() string   { return "current_schema()" }
+func (postgresDialect) Migrations() []Migration { return postgresMigrations }
+
+func (postgresDialect) MigrationTable() string {
+	return `
+	CREATE TABLE IF NOT EXISTS t_schema_migration (
+	  version integer PRIMARY KEY,
+	  name varchar(100) NOT NULL,
+	  applied_at timestamp NOT NULL
+	);
+	`
+}
+
+// LockMigrations 使用会话级 advisory lock，阻塞到获取为止（受 ctx 限制）
+func (postgresDialect) LockMigrations(ctx context.Context, conn *sql.Conn) (func(), error) {
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return nil, err
+	}
+	return func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+	}, nil
+}
+
+// Rebind 将 ? 依次替换为 $1, $2 ...（跳过字符串字面量中的 ?）
+func (postgresDialect) Rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	inString := false
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'':
+			inString = !inString
+			b.WriteByte(ch)
+		case ch == '?' && !inString:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+func (postgresDialect) ColumnUpgrades() []ColumnUpgrade {
+	return []ColumnUpgrade{
+		{"t_user", "status", `ALTER TABLE t_user ADD COLUMN status varchar(16) NOT NULL DEFAULT 'active'`},
+		{"t_user", "deleted_at", `ALTER TABLE t_user ADD COLUMN deleted_at timestamp DEFAULT NULL`},
+		{"t_user", "last_login_at", `ALTER TABLE t_user ADD COLUMN last_login_at timestamp DEFAULT NULL`},
+		{"t_user", "last_login_ip", `ALTER TABLE t_user ADD COLUMN last_login_ip varchar(64) DEFAULT ''`},
+		{"t_user", "created_by", `ALTER TABLE t_user ADD COLUMN created_by integer DEFAULT NULL`},
+		{"t_user", "updated_by", `ALTER TABLE t_user ADD COLUMN updated_by integer DEFAULT NULL`},
+		{"t_user", "role", `ALTER TABLE t_user ADD COLUMN role varchar(16) NOT NULL DEFAULT 'user'`},
+	}
+}
+
+// dialects 已支持的方言
+var dialects = map[string]Dialect{
+	"mysql":    mysqlDialect{},
+	"postgres": postgresDialect{},
+}
+
+// current 当前使用的方言，默认为MySQL
+var current Dialect = mysqlDialect{}
+
+// GetDialect 根据名称获取方言
+func GetDialect(name string) (Dialect, error) {
+	if name == "" {
+		name = "mysql"
+	}
+	d, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", name)
+	}
+	return d, nil
+}
+
+// CurrentDialect 获取当前使用的方言
+func CurrentDialect() Dialect {
+	return current
+}
+
+// Rebind 按当前方言转换SQL占位符
+func Rebind(query string) string {
+	return current.Rebind(query)
+}
+
+// Execer 兼容 *sql.DB、*sql.Conn 和 *sql.Tx
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// InsertReturningID 执行插入语句并返回自增主键
+// PostgreSQL 使用 RETURNING id，MySQL 使用 LastInsertId
+func InsertReturningID(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return InsertReturningIDOn(ctx, DB, query, args...)
+}
+
+// InsertReturningIDOn 在指定的连接或事务上执行插入语句并返回自增主键
+func InsertReturningIDOn(ctx context.Context, db Execer, query string, args ...interface{}) (int64, error) {
+	if current.SupportsReturning() {
+		var id int64
+		if err := db.QueryRowContext(ctx, Rebind(query)+" RETURNING id", args...).Scan(&id); err != nil {
+			return 0, err
+		}
+		return id, nil
+	}
+
+	result, err := db.ExecContext(ctx, Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Migration 数据库迁移，Version 递增，已发布的迁移不能修改，表结构变化追加新版本
+type Migration struct {
+	Version     int
+	Name        string
+	Up          MigrateFunc
+	Down        MigrateFunc
+	Destructive bool // 回滚时删除用户、会话、审计日志等无法恢复的数据，必须显式指定 force
+}
+
+// ErrDestructiveRollback 回滚会删除无法恢复的数据但未指定 force
+var ErrDestructiveRollback = errors.New("回滚会删除用户、会话和审计日志等全部数据")
+
+// MigrateFunc 在迁移专用的连接上执行迁移
+type MigrateFunc func(ctx context.Context, conn *sql.Conn) error
+
+// MigrationState 迁移的执行状态
+type MigrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"` // 为空表示未执行
+	Unknown   bool       `json:"unknown,omitempty"`    // 已执行但当前版本的程序中不存在（由更新的版本执行）
+}
+
+// Exec 依次执行 SQL 语句的迁移
+func Exec(stmts ...string) MigrateFunc {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		for _, stmt := range stmts {
+			if _, err := conn.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// createBaseTables 创建基础表，并为迁移前版本创建的表补充字段
+// 建表语句使用 IF NOT EXISTS，已有数据库首次执行迁移时不会报错
+func createBaseTables(stmts []string) MigrateFunc {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		if err := Exec(stmts...)(ctx, conn); err != nil {
+			return err
+		}
+		return upgradeColumns(ctx, conn)
+	}
+}
+
+// MigrateUp 按版本顺序执行未执行的迁移，steps 为 0 时执行全部，返回本次执行的迁移
+func MigrateUp(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range current.Migrations() {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(done) >= steps {
+				break
+			}
+			if err := m.Up(ctx, conn); err != nil {
+				return fmt.Errorf("执行迁移 %s 失败: %v", m, err)
+			}
+			query := Rebind(`INSERT INTO t_schema_migration (version, name, applied_at) VALUES (?, ?, ?)`)
+			if _, err := conn.ExecContext(ctx, query, m.Version, m.Name, FormatTime(time.Now())); err != nil {
+				return fmt.Errorf("记录迁移 %s 失败: %v", m, err)
+			}
+			log.Printf("已执行迁移 %s", m)
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateDown 按版本倒序回滚已执行的迁移，steps 为回滚的个数，返回本次回滚的迁移
+// 要回滚的迁移中包含 Destructive 迁移且 force 为 false 时不回滚任何迁移，返回 ErrDestructiveRollback
+func MigrateDown(ctx context.Context, steps int, force bool) ([]Migration, error) {
+	var done []Migration
+	err := withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		var pending []Migration
+		migrations := current.Migrations()
+		for i := len(migrations) - 1; i >= 0 && len(pending) < steps; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if m.Destructive && !force {
+				return fmt.Errorf("%w（迁移 %s）", ErrDestructiveRollback, m)
+			}
+			pending = append(pending, m)
+		}
+
+		for _, m := range pending {
+			if err := m.Down(ctx, conn); err != nil {
+				return fmt.Errorf("回滚迁移 %s 失败: %v", m, err)
+			}
+			query := Rebind(`DELETE FROM t_schema_migration WHERE version = ?`)
+			if _, err := conn.ExecContext(ctx, query, m.Version); err != nil {
+				return fmt.Errorf("删除迁移记录 %s 失败: %v", m, err)
+			}
+			log.Printf("已回滚迁移 %s", m)
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrationStatus 获取所有迁移的执行状态，按版本排序
+// 只读查询，不获取迁移锁，不会因其他实例正在执行迁移而阻塞
+func MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	applied := make(map[int]appliedMigration)
+
+	// 迁移记录表尚不存在时视为没有执行过任何迁移
+	var count int
+	query := Rebind(`SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = ` + current.CurrentSchema() + ` AND table_name = ?`)
+	if err := DB.QueryRowContext(ctx, query, "t_schema_migration").Scan(&count); err != nil {
+		return nil, fmt.Errorf("检查迁移记录表失败: %v", err)
+	}
+	if count > 0 {
+		var err error
+		if applied, err = appliedMigrations(ctx, DB); err != nil {
+			return nil, err
+		}
+	}
+
+	var states []MigrationState
+	for _, m := range current.Migrations() {
+		state := MigrationState{Version: m.Version, Name: m.Name}
+		if a, ok := applied[m.Version]; ok {
+			state.AppliedAt = &a.AppliedAt.Time
+			delete(applied, m.Version)
+		}
+		states = append(states, state)
+	}
+	for version, a := range applied {
+		states = append(states, MigrationState{Version: version, Name: a.Name, AppliedAt: &a.AppliedAt.Time, Unknown: true})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// PendingMigrations 未执行的迁移个数
+func PendingMigrations(ctx context.Context) (int, error) {
+	states, err := MigrationStatus(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, s := range states {
+		if s.AppliedAt == nil {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// appliedMigration 迁移记录
+type appliedMigration struct {
+	Name      string
+	AppliedAt sql.NullTime
+}
+
+// querier 兼容 *sql.DB 和 *sql.Conn
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// appliedMigrations 查询已执行的迁移
+func appliedMigrations(ctx context.Context, db querier) (map[int]appliedMigration, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, name, applied_at FROM t_schema_migration`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var (
+			version int
+			a       appliedMigration
+		)
+		if err := rows.Scan(&version, &a.Name, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// withMigrationLock 获取迁移锁后在同一连接上执行 fn，避免多个实例同时执行迁移
+func withMigrationLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, current.MigrationTable()); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %v", err)
+	}
+	unlock, err := current.LockMigrations(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("获取迁移锁失败: %v", err)
+	}
+	defer unlock()
+
+	return fn(conn)
+}
+
+// upgradeColumns 补充已存在表中缺少的字段
+func upgradeColumns(ctx context.Context, conn *sql.Conn) error {
+	query := Rebind(`SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = ` + current.CurrentSchema() + ` AND table_name = ? AND column_name = ?`)
+
+	for _, u := range current.ColumnUpgrades() {
+		var count int
+		if err := conn.QueryRowContext(ctx, query, u.Table, u.Column).Scan(&count); err != nil {
+			return fmt.Errorf("检查字段 %s.%s 失败: %v", u.Table, u.Column, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, u.DDL); err != nil {
+			return fmt.Errorf("添加字段 %s.%s 失败: %v", u.Table, u.Column, err)
+		}
+		log.Printf("已添加字段 %s.%s", u.Table, u.Column)
+	}
+	return nil
+}
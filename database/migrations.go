@@ -0,0 +1,233 @@
+package database
+
+// mysqlMigrations MySQL 数据库迁移
+var mysqlMigrations = []Migration{
+	{
+		Version:     1,
+		Name:        "create_base_tables",
+		Up:          createBaseTables(mysqlBaseTables),
+		Down:        Exec(dropBaseTables...),
+		Destructive: true,
+	},
+	{
+		Version: 2,
+		Name:    "create_job_tables",
+		Up:      Exec(mysqlJobTables...),
+		Down:    Exec(dropJobTables...),
+	},
+}
+
+// postgresMigrations PostgreSQL 数据库迁移，与 mysqlMigrations 的版本一一对应
+var postgresMigrations = []Migration{
+	{
+		Version:     1,
+		Name:        "create_base_tables",
+		Up:          createBaseTables(postgresBaseTables),
+		Down:        Exec(dropBaseTables...),
+		Destructive: true,
+	},
+	{
+		Version: 2,
+		Name:    "create_job_tables",
+		Up:      Exec(postgresJobTables...),
+		Down:    Exec(dropJobTables...),
+	},
+}
+
+// dropBaseTables 回滚迁移 1
+var dropBaseTables = []string{
+	`DROP TABLE IF EXISTS t_password_history`,
+	`DROP TABLE IF EXISTS t_session`,
+	`DROP TABLE IF EXISTS t_audit_log`,
+	`DROP TABLE IF EXISTS t_user`,
+}
+
+// dropJobTables 回滚迁移 2
+var dropJobTables = []string{
+	`DROP TABLE IF EXISTS t_job_run`,
+	`DROP TABLE IF EXISTS t_job_lock`,
+}
+
+// mysqlBaseTables 用户、审计日志、会话和密码历史表
+var mysqlBaseTables = []string{`
+	CREATE TABLE IF NOT EXISTS t_user (
+	  id int(11) NOT NULL AUTO_INCREMENT COMMENT 'id',
+	  username varchar(100) DEFAULT NULL COMMENT '用户名',
+	  password varchar(255) DEFAULT NULL COMMENT '密码',
+	  email varchar(32) DEFAULT '' COMMENT '邮箱',
+	  create_time datetime DEFAULT NULL COMMENT '创建时间',
+	  update_time datetime DEFAULT NULL COMMENT '更新时间',
+	  status varchar(16) NOT NULL DEFAULT 'active' COMMENT '状态: active/disabled/deleted',
+	  deleted_at datetime DEFAULT NULL COMMENT '删除时间',
+	  last_login_at datetime DEFAULT NULL COMMENT '最后登录时间',
+	  last_login_ip varchar(64) DEFAULT '' COMMENT '最后登录IP',
+	  created_by int(11) DEFAULT NULL COMMENT '创建人',
+	  updated_by int(11) DEFAULT NULL COMMENT '更新人',
+	  role varchar(16) NOT NULL DEFAULT 'user' COMMENT '角色: user/admin',
+	  PRIMARY KEY (id),
+	  UNIQUE KEY idx_user (username) USING BTREE
+	) ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8mb4 COMMENT='用户表';
+	`, `
+	CREATE TABLE IF NOT EXISTS t_audit_log (
+	  id bigint NOT NULL AUTO_INCREMENT COMMENT 'id',
+	  event_time datetime NOT NULL COMMENT '事件时间',
+	  event_type varchar(32) NOT NULL COMMENT '事件类型',
+	  outcome varchar(16) NOT NULL COMMENT '结果: success/failure',
+	  user_id int(11) DEFAULT NULL COMMENT '用户ID',
+	  username varchar(100) DEFAULT '' COMMENT '用户名',
+	  ip varchar(64) DEFAULT '' COMMENT '客户端IP',
+	  user_agent varchar(255) DEFAULT '' COMMENT 'User-Agent',
+	  request_id varchar(64) DEFAULT '' COMMENT '请求ID',
+	  reason varchar(255) DEFAULT '' COMMENT '失败原因',
+	  PRIMARY KEY (id),
+	  KEY idx_audit_user_time (user_id, event_time),
+	  KEY idx_audit_type_time (event_type, event_time),
+	  KEY idx_audit_time (event_time)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='安全审计日志';
+	`, `
+	CREATE TABLE IF NOT EXISTS t_session (
+	  id varchar(64) NOT NULL COMMENT '会话ID',
+	  user_id int(11) NOT NULL COMMENT '用户ID',
+	  device varchar(100) DEFAULT '' COMMENT '设备',
+	  ip varchar(64) DEFAULT '' COMMENT '登录IP',
+	  user_agent varchar(255) DEFAULT '' COMMENT 'User-Agent',
+	  create_time datetime NOT NULL COMMENT '创建时间',
+	  last_seen datetime NOT NULL COMMENT '最后活跃时间',
+	  last_ip varchar(64) DEFAULT '' COMMENT '最后活跃IP',
+	  expire_time datetime NOT NULL COMMENT '过期时间',
+	  revoked_at datetime DEFAULT NULL COMMENT '注销时间',
+	  PRIMARY KEY (id),
+	  KEY idx_session_user (user_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='登录会话';
+	`, `
+	CREATE TABLE IF NOT EXISTS t_password_history (
+	  id bigint NOT NULL AUTO_INCREMENT COMMENT 'id',
+	  user_id int(11) NOT NULL COMMENT '用户ID',
+	  password varchar(255) NOT NULL COMMENT '密码哈希',
+	  create_time datetime NOT NULL COMMENT '设置时间',
+	  PRIMARY KEY (id),
+	  KEY idx_password_history_user (user_id, id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='密码历史';
+	`}
+
+// mysqlJobTables 定时任务锁和执行历史表
+var mysqlJobTables = []string{`
+	CREATE TABLE IF NOT EXISTS t_job_lock (
+	  name varchar(64) NOT NULL COMMENT '任务名',
+	  owner varchar(128) NOT NULL DEFAULT '' COMMENT '持有锁的实例',
+	  locked_until datetime NOT NULL COMMENT '锁的到期时间',
+	  last_tick datetime DEFAULT NULL COMMENT '最近一次按计划执行的计划时间',
+	  PRIMARY KEY (name)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='定时任务锁';
+	`, `
+	CREATE TABLE IF NOT EXISTS t_job_run (
+	  id bigint NOT NULL AUTO_INCREMENT COMMENT 'id',
+	  job_name varchar(64) NOT NULL COMMENT '任务名',
+	  trigger_type varchar(16) NOT NULL COMMENT '触发方式: schedule/manual',
+	  owner varchar(128) NOT NULL DEFAULT '' COMMENT '执行的实例',
+	  start_time datetime NOT NULL COMMENT '开始时间',
+	  end_time datetime DEFAULT NULL COMMENT '结束时间',
+	  status varchar(16) NOT NULL COMMENT '状态: running/success/failed',
+	  result varchar(255) DEFAULT '' COMMENT '结果摘要',
+	  error varchar(1024) DEFAULT '' COMMENT '错误信息',
+	  PRIMARY KEY (id),
+	  KEY idx_job_run_name (job_name, id),
+	  KEY idx_job_run_start (start_time)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='定时任务执行历史';
+	`}
+
+// postgresBaseTables 用户、审计日志、会话和密码历史表
+var postgresBaseTables = []string{
+	`
+	CREATE TABLE IF NOT EXISTS t_user (
+	  id serial PRIMARY KEY,
+	  username varchar(100) DEFAULT NULL,
+	  password varchar(255) DEFAULT NULL,
+	  email varchar(32) DEFAULT '',
+	  create_time timestamp DEFAULT NULL,
+	  update_time timestamp DEFAULT NULL,
+	  status varchar(16) NOT NULL DEFAULT 'active',
+	  deleted_at timestamp DEFAULT NULL,
+	  last_login_at timestamp DEFAULT NULL,
+	  last_login_ip varchar(64) DEFAULT '',
+	  created_by integer DEFAULT NULL,
+	  updated_by integer DEFAULT NULL,
+	  role varchar(16) NOT NULL DEFAULT 'user',
+	  CONSTRAINT idx_user UNIQUE (username)
+	);
+	`,
+	`COMMENT ON TABLE t_user IS '用户表'`,
+	`
+	CREATE TABLE IF NOT EXISTS t_audit_log (
+	  id bigserial PRIMARY KEY,
+	  event_time timestamp NOT NULL,
+	  event_type varchar(32) NOT NULL,
+	  outcome varchar(16) NOT NULL,
+	  user_id integer DEFAULT NULL,
+	  username varchar(100) DEFAULT '',
+	  ip varchar(64) DEFAULT '',
+	  user_agent varchar(255) DEFAULT '',
+	  request_id varchar(64) DEFAULT '',
+	  reason varchar(255) DEFAULT ''
+	);
+	`,
+	`CREATE INDEX IF NOT EXISTS idx_audit_user_time ON t_audit_log (user_id, event_time)`,
+	`CREATE INDEX IF NOT EXISTS idx_audit_type_time ON t_audit_log (event_type, event_time)`,
+	`CREATE INDEX IF NOT EXISTS idx_audit_time ON t_audit_log (event_time)`,
+	`COMMENT ON TABLE t_audit_log IS '安全审计日志'`,
+	`
+	CREATE TABLE IF NOT EXISTS t_session (
+	  id varchar(64) PRIMARY KEY,
+	  user_id integer NOT NULL,
+	  device varchar(100) DEFAULT '',
+	  ip varchar(64) DEFAULT '',
+	  user_agent varchar(255) DEFAULT '',
+	  create_time timestamp NOT NULL,
+	  last_seen timestamp NOT NULL,
+	  last_ip varchar(64) DEFAULT '',
+	  expire_time timestamp NOT NULL,
+	  revoked_at timestamp DEFAULT NULL
+	);
+	`,
+	`CREATE INDEX IF NOT EXISTS idx_session_user ON t_session (user_id)`,
+	`COMMENT ON TABLE t_session IS '登录会话'`,
+	`
+	CREATE TABLE IF NOT EXISTS t_password_history (
+	  id bigserial PRIMARY KEY,
+	  user_id integer NOT NULL,
+	  password varchar(255) NOT NULL,
+	  create_time timestamp NOT NULL
+	);
+	`,
+	`CREATE INDEX IF NOT EXISTS idx_password_history_user ON t_password_history (user_id, id)`,
+	`COMMENT ON TABLE t_password_history IS '密码历史'`,
+}
+
+// postgresJobTables 定时任务锁和执行历史表
+var postgresJobTables = []string{
+	`
+	CREATE TABLE IF NOT EXISTS t_job_lock (
+	  name varchar(64) PRIMARY KEY,
+	  owner varchar(128) NOT NULL DEFAULT '',
+	  locked_until timestamp NOT NULL,
+	  last_tick timestamp DEFAULT NULL
+	);
+	`,
+	`COMMENT ON TABLE t_job_lock IS '定时任务锁'`,
+	`
+	CREATE TABLE IF NOT EXISTS t_job_run (
+	  id bigserial PRIMARY KEY,
+	  job_name varchar(64) NOT NULL,
+	  trigger_type varchar(16) NOT NULL,
+	  owner varchar(128) NOT NULL DEFAULT '',
+	  start_time timestamp NOT NULL,
+	  end_time timestamp DEFAULT NULL,
+	  status varchar(16) NOT NULL,
+	  result varchar(255) DEFAULT '',
+	  error varchar(1024) DEFAULT ''
+	);
+	`,
+	`CREATE INDEX IF NOT EXISTS idx_job_run_name ON t_job_run (job_name, id)`,
+	`CREATE INDEX IF NOT EXISTS idx_job_run_start ON t_job_run (start_time)`,
+	`COMMENT ON TABLE t_job_run IS '定时任务执行历史'`,
+}
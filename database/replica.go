@@ -0,0 +1,245 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-web/config"
+)
+
+// Replica 只读从库
+type Replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// Name 从库名称（host:port）
+func (r *Replica) Name() string {
+	return r.name
+}
+
+// DB 从库连接
+func (r *Replica) DB() *sql.DB {
+	return r.db
+}
+
+// Healthy 从库当前是否健康
+func (r *Replica) Healthy() bool {
+	return r.healthy.Load()
+}
+
+// Balancer 从库负载均衡策略
+// Pick 只会收到健康的从库，且列表不为空
+type Balancer interface {
+	Pick(replicas []*Replica) *Replica
+}
+
+// BalancerFunc 函数形式的负载均衡策略
+type BalancerFunc func(replicas []*Replica) *Replica
+
+// Pick 实现 Balancer 接口
+func (f BalancerFunc) Pick(replicas []*Replica) *Replica {
+	return f(replicas)
+}
+
+var (
+	balancersMu sync.RWMutex
+	balancers   = map[string]func() Balancer{
+		"round_robin": func() Balancer { return &roundRobinBalancer{} },
+		"random":      func() Balancer { return BalancerFunc(pickRandom) },
+		"least_conn":  func() Balancer { return BalancerFunc(pickLeastConn) },
+	}
+)
+
+// RegisterBalancer 注册自定义负载均衡策略，需在 InitDB 之前调用
+func RegisterBalancer(name string, factory func() Balancer) {
+	balancersMu.Lock()
+	defer balancersMu.Unlock()
+	balancers[name] = factory
+}
+
+// newBalancer 根据名称创建负载均衡策略
+func newBalancer(name string) (Balancer, error) {
+	balancersMu.RLock()
+	factory, ok := balancers[name]
+	balancersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不支持的从库负载均衡策略: %s", name)
+	}
+	return factory(), nil
+}
+
+// roundRobinBalancer 轮询策略
+type roundRobinBalancer struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobinBalancer) Pick(replicas []*Replica) *Replica {
+	n := b.next.Add(1)
+	return replicas[int(n%uint64(len(replicas)))]
+}
+
+// pickRandom 随机策略
+func pickRandom(replicas []*Replica) *Replica {
+	return replicas[rand.Intn(len(replicas))]
+}
+
+// pickLeastConn 最少使用中连接策略
+func pickLeastConn(replicas []*Replica) *Replica {
+	best := replicas[0]
+	bestInUse := best.db.Stats().InUse
+	for _, r := range replicas[1:] {
+		if inUse := r.db.Stats().InUse; inUse < bestInUse {
+			best, bestInUse = r, inUse
+		}
+	}
+	return best
+}
+
+var (
+	// replicas 所有从库
+	replicas []*Replica
+	// balancer 当前负载均衡策略
+	balancer Balancer
+	// stopHealthCheck 停止健康检查
+	stopHealthCheck chan struct{}
+	// healthCheckDone 健康检查协程已退出
+	healthCheckDone chan struct{}
+)
+
+// primaryKey 上下文中强制使用主库的标记
+type primaryKey struct{}
+
+// UsePrimary 标记上下文中的读操作强制走主库，用于写后读等需要强一致的场景
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Reader 获取用于读操作的连接
+// 没有可用从库或上下文要求走主库时返回主库
+func Reader(ctx context.Context) *sql.DB {
+	if pinned, _ := ctx.Value(primaryKey{}).(bool); pinned {
+		return DB
+	}
+
+	healthy := make([]*Replica, 0, len(replicas))
+	for _, r := range replicas {
+		if r.Healthy() {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return DB
+	}
+	return balancer.Pick(healthy).db
+}
+
+// Replicas 获取所有从库（含已摘除的）
+func Replicas() []*Replica {
+	return replicas
+}
+
+// initReplicas 连接所有从库并启动健康检查
+func initReplicas(dbCfg config.DatabaseConfig) error {
+	if len(dbCfg.Replicas) == 0 {
+		return nil
+	}
+
+	policy := dbCfg.ReplicaPolicy
+	if policy == "" {
+		policy = "round_robin"
+	}
+	b, err := newBalancer(policy)
+	if err != nil {
+		return err
+	}
+	balancer = b
+
+	for _, rc := range dbCfg.Replicas {
+		replicaCfg := dbCfg.ForReplica(rc)
+		db, err := sql.Open(current.DriverName(), replicaCfg.DSN())
+		if err != nil {
+			return fmt.Errorf("连接从库失败: %v", err)
+		}
+		configurePool(db, replicaCfg)
+
+		r := &Replica{
+			name: net.JoinHostPort(replicaCfg.Host, replicaCfg.Port),
+			db:   db,
+		}
+		// 启动时不可用的从库先不参与读请求，由健康检查恢复
+		if err := db.Ping(); err != nil {
+			log.Printf("从库 %s 连接失败，暂时摘除: %v", r.name, err)
+		} else {
+			r.healthy.Store(true)
+		}
+		replicas = append(replicas, r)
+	}
+
+	interval := dbCfg.HealthCheckInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	stopHealthCheck = make(chan struct{})
+	healthCheckDone = make(chan struct{})
+	go runHealthCheck(interval, stopHealthCheck, healthCheckDone)
+
+	log.Printf("已连接 %d 个从库，负载均衡策略: %s", len(replicas), policy)
+	return nil
+}
+
+// runHealthCheck 定期检查从库，失败时摘除，恢复后重新加入
+func runHealthCheck(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, r := range replicas {
+				checkReplica(r, interval)
+			}
+		}
+	}
+}
+
+// checkReplica 检查单个从库并更新健康状态
+func checkReplica(r *Replica, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := r.db.PingContext(ctx)
+	healthy := err == nil
+	if r.healthy.Swap(healthy) != healthy {
+		if healthy {
+			log.Printf("从库 %s 已恢复", r.name)
+		} else {
+			log.Printf("从库 %s 健康检查失败，已摘除: %v", r.name, err)
+		}
+	}
+}
+
+// closeReplicas 停止健康检查并关闭所有从库连接
+func closeReplicas() {
+	if stopHealthCheck != nil {
+		close(stopHealthCheck)
+		<-healthCheckDone
+		stopHealthCheck = nil
+	}
+	for _, r := range replicas {
+		r.db.Close()
+	}
+	replicas = nil
+}
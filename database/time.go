@@ -0,0 +1,41 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"golang-web/config"
+)
+
+// timeLayout 时间字段的写入格式
+const timeLayout = "2006-01-02 15:04:05"
+
+// location 写入时间字段使用的时区，与驱动读取时间字段的时区一致
+var location = time.Local
+
+// FormatTime 按数据库时区格式化时间，所有写入和比较时间字段的参数都应使用它
+// 读出的时间才能与 time.Now() 直接比较（会话过期、空闲超时、任务锁租期等）
+func FormatTime(t time.Time) string {
+	return t.In(location).Format(timeLayout)
+}
+
+// setLocation 按驱动读取时间字段的方式确定写入时区
+// MySQL 驱动按 loc 参数（database.time_zone）解析 DATETIME；
+// lib/pq 把不带时区的 timestamp 按 UTC 解析，因此 PostgreSQL 统一按 UTC 写入，
+// database.time_zone 只影响会话时区（now() 等）
+func setLocation(dbCfg config.DatabaseConfig) error {
+	if dbCfg.Driver == "postgres" {
+		location = time.UTC
+		return nil
+	}
+	if dbCfg.TimeZone == "" || dbCfg.TimeZone == "Local" {
+		location = time.Local
+		return nil
+	}
+	loc, err := time.LoadLocation(dbCfg.TimeZone)
+	if err != nil {
+		return fmt.Errorf("无效的数据库时区 %q: %v", dbCfg.TimeZone, err)
+	}
+	location = loc
+	return nil
+}
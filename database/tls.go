@@ -0,0 +1,106 @@
+package database
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang-web/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// registerMySQLTLS 按配置向 MySQL 驱动注册自定义TLS配置
+// PostgreSQL 的TLS参数直接写在DSN中，无需注册
+func registerMySQLTLS(dbCfg config.DatabaseConfig) error {
+	switch dbCfg.TLS.Mode {
+	case "", "disable", "preferred":
+		return nil
+	case "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("不支持的TLS模式: %s", dbCfg.TLS.Mode)
+	}
+
+	tlsConfig, err := buildTLSConfig(dbCfg)
+	if err != nil {
+		return err
+	}
+	return mysql.RegisterTLSConfig(config.MySQLTLSConfigName, tlsConfig)
+}
+
+// buildTLSConfig 根据TLS模式构建 tls.Config
+func buildTLSConfig(dbCfg config.DatabaseConfig) (*tls.Config, error) {
+	tlsCfg := dbCfg.TLS
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	// 客户端证书
+	if tlsCfg.CertFile != "" || tlsCfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载数据库客户端证书失败: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// CA证书
+	var pool *x509.CertPool
+	if tlsCfg.CAFile != "" {
+		pem, err := os.ReadFile(tlsCfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取数据库CA证书失败: %v", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("解析数据库CA证书失败")
+		}
+	}
+
+	switch tlsCfg.Mode {
+	case "require":
+		// 仅加密，不校验服务端证书
+		tlsConfig.InsecureSkipVerify = true
+	case "verify-ca":
+		// 校验证书链但不校验主机名
+		if pool == nil {
+			return nil, errors.New("TLS模式 verify-ca 需要配置 ca_file")
+		}
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyChain(pool)
+	case "verify-full":
+		// ServerName 为空时驱动会按各连接的主机名校验，主库和从库可共用同一配置
+		tlsConfig.RootCAs = pool
+		tlsConfig.ServerName = tlsCfg.ServerName
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyChain 返回仅校验证书链的校验函数
+func verifyChain(pool *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("数据库服务端未提供证书")
+		}
+
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, cert)
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         pool,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(opts)
+		return err
+	}
+}
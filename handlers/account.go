@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-web/audit"
+	"golang-web/database"
+	"golang-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler 账户自助管理处理器，路由需要使用 RequireRecentAuth
+type AccountHandler struct{}
+
+// NewAccountHandler 创建新的账户处理器
+func NewAccountHandler() *AccountHandler {
+	return &AccountHandler{}
+}
+
+// ChangeEmail 修改当前用户的邮箱
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	var req models.ChangeEmailRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	user, err := models.GetUserByID(database.UsePrimary(c.Request.Context()), c.GetInt("user_id"))
+	if err != nil {
+		respondDBError(c, "获取用户信息失败", err)
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "用户不存在",
+		})
+		return
+	}
+
+	if err := models.UpdateEmail(c.Request.Context(), user.ID, req.Email, user.ID); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": "用户不存在",
+			})
+			return
+		}
+		respondDBError(c, "修改邮箱失败", err)
+		return
+	}
+
+	audit.RecordGin(c, audit.EventEmailChange, audit.OutcomeSuccess, user.ID, user.Username, user.Email+" -> "+req.Email)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "邮箱修改成功",
+	})
+}
+
+// DeleteAccount 注销当前用户的账号（软删除），并注销其所有会话
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	username := c.GetString("username")
+
+	if err := models.SoftDeleteUser(c.Request.Context(), userID, userID); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": "用户不存在",
+			})
+			return
+		}
+		respondDBError(c, "注销账号失败", err)
+		return
+	}
+
+	if err := models.RevokeUserSessions(c.Request.Context(), userID, ""); err != nil {
+		respondDBError(c, "注销会话失败", err)
+		return
+	}
+
+	audit.RecordGin(c, audit.EventAccountDelete, audit.OutcomeSuccess, userID, username, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "账号已注销",
+	})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang-web/audit"
+	"golang-web/config"
+	"golang-web/database"
+	"golang-web/models"
+	"golang-web/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler 管理员处理器
+type AdminHandler struct {
+	cfg *config.Holder
+}
+
+// NewAdminHandler 创建新的管理员处理器
+func NewAdminHandler(cfg *config.Holder) *AdminHandler {
+	return &AdminHandler{
+		cfg: cfg,
+	}
+}
+
+// ResetPassword 重置指定用户的密码，并注销该用户的所有会话
+func (h *AdminHandler) ResetPassword(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的用户ID",
+		})
+		return
+	}
+
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	user, err := models.GetUserByID(database.UsePrimary(c.Request.Context()), userID)
+	if err != nil {
+		respondDBError(c, "获取用户信息失败", err)
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "用户不存在",
+		})
+		return
+	}
+
+	if !checkNewPassword(c, security.NewPolicy(h.cfg.Get().Password), user, req.NewPassword, user.Username, user.Email) {
+		return
+	}
+
+	if err := models.UpdatePassword(c.Request.Context(), user.ID, req.NewPassword, c.GetInt("user_id")); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": "用户不存在",
+			})
+			return
+		}
+		respondDBError(c, "重置密码失败", err)
+		return
+	}
+
+	if err := models.RevokeUserSessions(c.Request.Context(), user.ID, ""); err != nil {
+		respondDBError(c, "注销用户会话失败", err)
+		return
+	}
+
+	audit.RecordGin(c, audit.EventPasswordReset, audit.OutcomeSuccess, user.ID, user.Username,
+		"操作人: "+c.GetString("username"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "密码重置成功",
+	})
+}
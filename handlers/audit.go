@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang-web/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler 审计日志处理器
+type AuditHandler struct{}
+
+// NewAuditHandler 创建新的审计日志处理器
+func NewAuditHandler() *AuditHandler {
+	return &AuditHandler{}
+}
+
+// maxAuditLimit 单次查询最多返回的审计事件数
+const maxAuditLimit = 1000
+
+// ListEvents 查询审计事件
+// 支持参数: user_id、type、from、to（RFC3339 或 2006-01-02 15:04:05）、limit
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	events, err := audit.Query(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, audit.ErrNotQueryable) {
+			c.JSON(http.StatusNotImplemented, gin.H{
+				"code":    501,
+				"message": err.Error(),
+			})
+			return
+		}
+		respondDBError(c, "查询审计日志失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    events,
+	})
+}
+
+// parseAuditFilter 解析审计查询参数
+func parseAuditFilter(c *gin.Context) (audit.Filter, error) {
+	var (
+		filter audit.Filter
+		err    error
+	)
+
+	if v := c.Query("user_id"); v != "" {
+		if filter.UserID, err = strconv.Atoi(v); err != nil {
+			return filter, errors.New("user_id 必须为整数")
+		}
+	}
+	filter.Type = c.Query("type")
+	if v := c.Query("from"); v != "" {
+		if filter.From, err = parseTime(v); err != nil {
+			return filter, errors.New("from 时间格式错误")
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if filter.To, err = parseTime(v); err != nil {
+			return filter, errors.New("to 时间格式错误")
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 0 {
+			return filter, errors.New("limit 必须为正整数")
+		}
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	return filter, nil
+}
+
+// parseTime 解析 RFC3339 或本地时间格式
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", v, time.Local)
+}
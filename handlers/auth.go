@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
+	"golang-web/audit"
 	"golang-web/config"
+	"golang-web/database"
 	"golang-web/models"
+	"golang-web/security"
 	"golang-web/utils"
 
 	"github.com/gin-gonic/gin"
@@ -13,11 +19,12 @@ import (
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	cfg *config.Config
+	cfg *config.Holder
 }
 
 // NewAuthHandler 创建新的认证处理器
-func NewAuthHandler(cfg *config.Config) *AuthHandler {
+// 配置通过 Holder 按请求读取，JWT、会话和密码策略的修改可以热加载生效
+func NewAuthHandler(cfg *config.Holder) *AuthHandler {
 	return &AuthHandler{
 		cfg: cfg,
 	}
@@ -38,18 +45,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// 根据用户名查找用户
-	user, err := models.GetUserByUsername(req.Username)
+	user, err := models.GetUserByUsername(c.Request.Context(), req.Username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "服务器内部错误",
-			"error":   err.Error(),
-		})
+		respondDBError(c, "服务器内部错误", err)
 		return
 	}
 
 	// 检查用户是否存在
 	if user == nil {
+		audit.RecordGin(c, audit.EventLogin, audit.OutcomeFailure, 0, req.Username, "用户不存在")
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
 			"message": "用户名或密码错误",
@@ -59,6 +63,17 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// 验证密码
 	if !user.ValidatePassword(req.Password) {
+		audit.RecordGin(c, audit.EventLogin, audit.OutcomeFailure, user.ID, user.Username, "密码错误")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "用户名或密码错误",
+		})
+		return
+	}
+
+	// 检查账号状态，与密码错误返回相同的响应，避免泄露被禁用账号的密码是否正确
+	if !user.IsActive() {
+		audit.RecordGin(c, audit.EventLogin, audit.OutcomeFailure, user.ID, user.Username, "账号已被禁用")
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
 			"message": "用户名或密码错误",
@@ -66,8 +81,31 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// 账号可用时才透明升级过时的密码哈希，失败不影响登录
+	if user.NeedsRehash() {
+		if err := models.RehashPassword(c.Request.Context(), user, req.Password); err != nil {
+			log.Printf("升级用户 %d 的密码哈希失败: %v", user.ID, err)
+		}
+	}
+
+	// 创建登录会话
+	cfg := h.cfg.Get()
+	device := req.Device
+	if device == "" {
+		device = utils.DeviceFromUserAgent(c.Request.UserAgent())
+	}
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	session, err := models.CreateSession(c.Request.Context(), user.ID, device, c.ClientIP(), userAgent, utils.TokenExpiry(cfg))
+	if err != nil {
+		respondDBError(c, "创建会话失败", err)
+		return
+	}
+
 	// 生成JWT令牌
-	token, err := utils.GenerateToken(user.ID, user.Username, h.cfg)
+	token, err := utils.GenerateToken(user.ID, user.Username, cfg, utils.WithSessionID(session.ID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -77,6 +115,16 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// 记录最后登录信息，失败不影响登录
+	now := time.Now()
+	if err := models.UpdateLastLogin(c.Request.Context(), user.ID, c.ClientIP()); err != nil {
+		log.Printf("更新用户 %d 最后登录信息失败: %v", user.ID, err)
+	} else {
+		user.LastLoginAt = &now
+		user.LastLoginIP = c.ClientIP()
+	}
+	audit.RecordGin(c, audit.EventLogin, audit.OutcomeSuccess, user.ID, user.Username, "")
+
 	// 返回登录成功响应
 	response := models.LoginResponse{
 		Token: token,
@@ -104,9 +152,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	// 校验密码安全策略
+	if !checkNewPassword(c, security.NewPolicy(h.cfg.Get().Password), nil, req.Password, req.Username, req.Email) {
+		audit.RecordGin(c, audit.EventRegister, audit.OutcomeFailure, 0, req.Username, "密码不符合安全策略")
+		return
+	}
+
 	// 创建新用户
-	user, err := models.CreateUser(&req)
+	user, err := models.CreateUser(c.Request.Context(), &req)
 	if err != nil {
+		audit.RecordGin(c, audit.EventRegister, audit.OutcomeFailure, 0, req.Username, err.Error())
+		if isContextError(err) {
+			respondDBError(c, "注册失败", err)
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
 			"message": "注册失败",
@@ -115,6 +174,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	audit.RecordGin(c, audit.EventRegister, audit.OutcomeSuccess, user.ID, user.Username, "")
+
 	// 返回注册成功响应
 	c.JSON(http.StatusCreated, gin.H{
 		"code":    201,
@@ -136,13 +197,9 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	}
 
 	// 根据用户ID查找用户
-	user, err := models.GetUserByID(userID.(int))
+	user, err := models.GetUserByID(c.Request.Context(), userID.(int))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "获取用户信息失败",
-			"error":   err.Error(),
-		})
+		respondDBError(c, "获取用户信息失败", err)
 		return
 	}
 
@@ -187,8 +244,18 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	tokenString := tokenParts[1]
 
 	// 刷新令牌
-	newToken, err := utils.RefreshToken(tokenString, h.cfg)
+	cfg := h.cfg.Get()
+	newToken, expiresAt, err := utils.RefreshToken(tokenString, cfg)
 	if err != nil {
+		audit.RecordGin(c, audit.EventTokenRefresh, audit.OutcomeFailure, c.GetInt("user_id"), c.GetString("username"), err.Error())
+		if errors.Is(err, utils.ErrSessionLifetimeExceeded) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": "登录已超过最长有效期，请重新登录",
+				"error":   err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
 			"message": "刷新令牌失败",
@@ -197,12 +264,101 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	// 延长会话有效期
+	if sessionID := c.GetString("session_id"); sessionID != "" {
+		if err := models.ExtendSession(c.Request.Context(), sessionID, expiresAt); err != nil {
+			log.Printf("延长会话 %s 有效期失败: %v", sessionID, err)
+		}
+	}
+
+	audit.RecordGin(c, audit.EventTokenRefresh, audit.OutcomeSuccess, c.GetInt("user_id"), c.GetString("username"), "")
+
 	// 返回新令牌
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "令牌刷新成功",
 		"data": gin.H{
-			"token": newToken,
+			"token":      newToken,
+			"expires_at": expiresAt,
+		},
+	})
+}
+
+// Reauth 重新验证当前用户的密码，签发 auth_time 为当前时间的新令牌，用于修改密码、邮箱和注销账号等敏感操作
+// 新令牌沿用原令牌的会话、过期时间和授权范围
+func (h *AuthHandler) Reauth(c *gin.Context) {
+	var req models.ReauthRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	user, err := models.GetUserByID(database.UsePrimary(c.Request.Context()), c.GetInt("user_id"))
+	if err != nil {
+		respondDBError(c, "获取用户信息失败", err)
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "用户不存在",
+		})
+		return
+	}
+
+	// 验证密码，错误时不返回 401，避免客户端误认为令牌已失效
+	if !user.ValidatePassword(req.Password) {
+		audit.RecordGin(c, audit.EventReauth, audit.OutcomeFailure, user.ID, user.Username, "密码错误")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "密码错误",
+		})
+		return
+	}
+	if !user.IsActive() {
+		audit.RecordGin(c, audit.EventReauth, audit.OutcomeFailure, user.ID, user.Username, "账号已被禁用")
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "账号已被禁用",
+		})
+		return
+	}
+
+	// 透明升级过时的密码哈希，失败不影响验证
+	if user.NeedsRehash() {
+		if err := models.RehashPassword(c.Request.Context(), user, req.Password); err != nil {
+			log.Printf("升级用户 %d 的密码哈希失败: %v", user.ID, err)
+		}
+	}
+
+	claims := c.MustGet("claims").(*utils.Claims)
+	authTime := time.Now()
+	token, err := utils.GenerateToken(user.ID, user.Username, h.cfg.Get(),
+		utils.WithClaimsFrom(claims), utils.WithAuthTime(authTime), utils.WithExpiry(claims.ExpiresAt.Time))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "生成令牌失败",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	audit.RecordGin(c, audit.EventReauth, audit.OutcomeSuccess, user.ID, user.Username, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "验证成功",
+		"data": gin.H{
+			"token":      token,
+			"auth_time":  authTime,
+			"expires_at": claims.ExpiresAt.Time,
 		},
 	})
 }
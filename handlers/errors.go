@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest 客户端已断开连接（沿用 nginx 的 499 约定）
+const StatusClientClosedRequest = 499
+
+// isContextError 判断错误是否由超时或请求取消引起
+func isContextError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// respondDBError 返回数据库错误响应，超时和请求取消单独区分
+func respondDBError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"code":    504,
+			"message": "数据库查询超时",
+			"error":   err.Error(),
+		})
+	case errors.Is(err, context.Canceled):
+		c.JSON(StatusClientClosedRequest, gin.H{
+			"code":    499,
+			"message": "请求已取消",
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": message,
+			"error":   err.Error(),
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang-web/audit"
+	"golang-web/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler 定时任务管理处理器
+type JobHandler struct {
+	sched *scheduler.Scheduler
+}
+
+// NewJobHandler 创建新的定时任务管理处理器
+func NewJobHandler(sched *scheduler.Scheduler) *JobHandler {
+	return &JobHandler{sched: sched}
+}
+
+// maxJobRunLimit 单次查询最多返回的执行记录数
+const maxJobRunLimit = 200
+
+// ListJobs 列出所有定时任务、下次执行时间和最近一次执行记录
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.sched.Jobs(c.Request.Context())
+	if err != nil {
+		respondDBError(c, "获取定时任务失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    jobs,
+	})
+}
+
+// RunJob 立即在后台执行定时任务，通过执行记录查看结果
+func (h *JobHandler) RunJob(c *gin.Context) {
+	name := c.Param("name")
+	run, err := h.sched.Trigger(name)
+	if err != nil {
+		if !respondJobError(c, err) {
+			respondDBError(c, "触发定时任务失败", err)
+		}
+		audit.RecordGin(c, audit.EventJobTrigger, audit.OutcomeFailure, c.GetInt("user_id"), c.GetString("username"),
+			name+": "+err.Error())
+		return
+	}
+
+	audit.RecordGin(c, audit.EventJobTrigger, audit.OutcomeSuccess, c.GetInt("user_id"), c.GetString("username"), name)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"code":    202,
+		"message": "任务已开始执行",
+		"data":    run,
+	})
+}
+
+// ListRuns 获取定时任务最近的执行记录，支持参数 limit（默认 20）
+func (h *JobHandler) ListRuns(c *gin.Context) {
+	limit := 20
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "请求参数错误",
+				"error":   "limit 必须为正整数",
+			})
+			return
+		}
+		limit = min(n, maxJobRunLimit)
+	}
+
+	runs, err := h.sched.Runs(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		if !respondJobError(c, err) {
+			respondDBError(c, "获取执行记录失败", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    runs,
+	})
+}
+
+// respondJobError 返回调度器的业务错误响应，不是业务错误时返回 false
+func respondJobError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, scheduler.ErrJobRunning):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": err.Error(),
+		})
+	case errors.Is(err, scheduler.ErrStopped):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": err.Error(),
+		})
+	default:
+		return false
+	}
+	return true
+}
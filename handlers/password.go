@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-web/audit"
+	"golang-web/database"
+	"golang-web/models"
+	"golang-web/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkNewPassword 按密码安全策略校验新密码，不通过时写入响应并返回 false
+// user 为空表示注册场景，不检查密码历史
+func checkNewPassword(c *gin.Context, policy *security.Policy, user *models.User, password, username, email string) bool {
+	err := policy.Validate(password, username, email)
+	if err == nil && user != nil {
+		var used bool
+		used, err = models.PasswordUsedRecently(c.Request.Context(), user, password, policy.HistorySize())
+		if err == nil && used {
+			err = &security.PolicyError{Violations: []string{"不能与最近使用过的密码相同"}}
+		}
+	}
+	if err == nil {
+		return true
+	}
+
+	var policyErr *security.PolicyError
+	if errors.As(err, &policyErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":       400,
+			"message":    "密码不符合安全策略",
+			"error":      policyErr.Error(),
+			"violations": policyErr.Violations,
+		})
+		return false
+	}
+
+	respondDBError(c, "校验密码失败", err)
+	return false
+}
+
+// ChangePassword 修改当前用户密码，成功后注销其他会话
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	user, err := models.GetUserByID(database.UsePrimary(c.Request.Context()), c.GetInt("user_id"))
+	if err != nil {
+		respondDBError(c, "获取用户信息失败", err)
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "用户不存在",
+		})
+		return
+	}
+
+	// 验证原密码
+	if !user.ValidatePassword(req.OldPassword) {
+		audit.RecordGin(c, audit.EventPasswordChange, audit.OutcomeFailure, user.ID, user.Username, "原密码错误")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "原密码错误",
+		})
+		return
+	}
+
+	if !checkNewPassword(c, security.NewPolicy(h.cfg.Get().Password), user, req.NewPassword, user.Username, user.Email) {
+		return
+	}
+
+	if err := models.UpdatePassword(c.Request.Context(), user.ID, req.NewPassword, user.ID); err != nil {
+		respondDBError(c, "修改密码失败", err)
+		return
+	}
+
+	// 注销其他设备上的会话
+	if err := models.RevokeUserSessions(c.Request.Context(), user.ID, c.GetString("session_id")); err != nil {
+		respondDBError(c, "注销其他会话失败", err)
+		return
+	}
+
+	audit.RecordGin(c, audit.EventPasswordChange, audit.OutcomeSuccess, user.ID, user.Username, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "密码修改成功",
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-web/audit"
+	"golang-web/config"
+	"golang-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler 登录会话处理器
+type SessionHandler struct {
+	cfg *config.Holder
+}
+
+// NewSessionHandler 创建新的会话处理器
+func NewSessionHandler(cfg *config.Holder) *SessionHandler {
+	return &SessionHandler{cfg: cfg}
+}
+
+// ListSessions 获取当前用户的有效会话
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	sessions, err := models.ListActiveSessions(c.Request.Context(), c.GetInt("user_id"), h.cfg.Get().Session)
+	if err != nil {
+		respondDBError(c, "获取会话列表失败", err)
+		return
+	}
+
+	currentID := c.GetString("session_id")
+	for _, s := range sessions {
+		s.Current = s.ID == currentID
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    sessions,
+	})
+}
+
+// RevokeSession 注销当前用户的指定会话，注销后该会话的令牌立即失效
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	sessionID := c.Param("id")
+
+	if err := models.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": "会话不存在",
+			})
+			return
+		}
+		respondDBError(c, "注销会话失败", err)
+		return
+	}
+
+	audit.RecordGin(c, audit.EventSessionRevoke, audit.OutcomeSuccess, userID, c.GetString("username"), sessionID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "会话已注销",
+	})
+}
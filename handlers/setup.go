@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+
+	"golang-web/audit"
+	"golang-web/config"
+	"golang-web/models"
+	"golang-web/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupHandler 首次初始化处理器
+type SetupHandler struct {
+	cfg   *config.Holder
+	token string
+}
+
+// NewSetupHandler 创建新的初始化处理器
+// 未配置 setup.token 且尚无管理员时，生成一次性令牌并打印到日志
+func NewSetupHandler(cfg *config.Holder) *SetupHandler {
+	h := &SetupHandler{
+		cfg:   cfg,
+		token: cfg.Get().Setup.Token,
+	}
+	if h.token != "" {
+		return h
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("生成初始化令牌失败，初始化接口不可用: %v", err)
+		return h
+	}
+	h.token = hex.EncodeToString(b)
+
+	exists, err := models.AdminExists(context.Background())
+	if err != nil {
+		log.Printf("检查管理员账号失败: %v", err)
+		return h
+	}
+	if !exists {
+		log.Printf("尚未创建管理员，请使用初始化令牌调用 POST /api/v1/setup: %s", h.token)
+	}
+	return h
+}
+
+// Setup 使用初始化令牌创建第一个管理员，已存在管理员后不可再用
+func (h *SetupHandler) Setup(c *gin.Context) {
+	var req models.BootstrapAdminRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// 校验初始化令牌
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(req.SetupToken), []byte(h.token)) != 1 {
+		audit.RecordGin(c, audit.EventSetup, audit.OutcomeFailure, 0, req.Username, "初始化令牌无效")
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "初始化令牌无效",
+		})
+		return
+	}
+
+	if !checkNewPassword(c, security.NewPolicy(h.cfg.Get().Password), nil, req.Password, req.Username, req.Email) {
+		return
+	}
+
+	user, err := models.BootstrapAdmin(c.Request.Context(), req.Username, req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, models.ErrAdminExists) {
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    409,
+				"message": err.Error(),
+			})
+			return
+		}
+		if isContextError(err) {
+			respondDBError(c, "初始化失败", err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "初始化失败",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	audit.RecordGin(c, audit.EventSetup, audit.OutcomeSuccess, user.ID, user.Username, "")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    201,
+		"message": "管理员创建成功",
+		"data":    user,
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golang-web/config"
+	"golang-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler 令牌内省处理器
+type TokenHandler struct {
+	cfg *config.Holder
+}
+
+// NewTokenHandler 创建新的令牌内省处理器
+func NewTokenHandler(cfg *config.Holder) *TokenHandler {
+	return &TokenHandler{cfg: cfg}
+}
+
+// Introspect 令牌内省（RFC 7662），供下游服务和运维人员在不持有签名密钥的情况下检查令牌
+// 响应体按 RFC 7662 直接返回内省结果，无效令牌同样返回 200 和 active=false
+// 客户端只能内省受众在其 audiences 配置中的令牌，管理员可以内省所有已知受众的令牌
+func (h *TokenHandler) Introspect(c *gin.Context) {
+	var req models.IntrospectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	cfg := h.cfg.Get()
+	audiences := cfg.KnownAudiences()
+	if clientID := c.GetString("client_id"); clientID != "" {
+		audiences = cfg.ClientAudiences(clientID)
+	}
+
+	result, err := models.IntrospectToken(c.Request.Context(), req.Token, cfg, audiences)
+	if err != nil {
+		respondDBError(c, "校验会话失败", err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, result)
+}
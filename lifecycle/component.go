@@ -0,0 +1,47 @@
+package lifecycle
+
+import "context"
+
+// Component 由 App 管理启动和停止的组件
+type Component interface {
+	// Name 组件名称，用于声明依赖和日志
+	Name() string
+	// Start 启动组件，应尽快返回；ctx 在应用关闭时取消，后台任务应使用它
+	Start(ctx context.Context) error
+	// Stop 停止组件并释放资源，ctx 的截止时间为应用的关闭期限
+	Stop(ctx context.Context) error
+}
+
+// Exiter 可以主动要求应用退出的组件，如 HTTP 服务异常退出或平滑重启完成
+// Exit 返回的通道收到 nil 表示正常退出，收到错误表示异常退出
+type Exiter interface {
+	Exit() <-chan error
+}
+
+// funcComponent 由函数组成的组件
+type funcComponent struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+// Func 用启动和停止函数创建组件，不需要的函数可以为 nil
+func Func(name string, start, stop func(ctx context.Context) error) Component {
+	return &funcComponent{name: name, start: start, stop: stop}
+}
+
+func (f *funcComponent) Name() string { return f.name }
+
+func (f *funcComponent) Start(ctx context.Context) error {
+	if f.start == nil {
+		return nil
+	}
+	return f.start(ctx)
+}
+
+func (f *funcComponent) Stop(ctx context.Context) error {
+	if f.stop == nil {
+		return nil
+	}
+	return f.stop(ctx)
+}
@@ -0,0 +1,201 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// App 管理组件的启动和停止
+// 组件按依赖顺序启动（同级按注册顺序），按相反顺序停止；所有组件共享一个在关闭时取消的根上下文
+type App struct {
+	shutdownTimeout time.Duration
+
+	components []*entry
+	started    []*entry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	exitOnce sync.Once
+	exitErr  error
+	exit     chan struct{}
+}
+
+// entry 注册的组件及其依赖
+type entry struct {
+	component Component
+	deps      []string
+}
+
+// New 创建应用，shutdownTimeout 为停止所有组件的总期限，0 表示不限制
+func New(shutdownTimeout time.Duration) *App {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &App{
+		shutdownTimeout: shutdownTimeout,
+		ctx:             ctx,
+		cancel:          cancel,
+		exit:            make(chan struct{}),
+	}
+}
+
+// Register 注册组件，dependsOn 中的组件会先于它启动、晚于它停止
+func (a *App) Register(c Component, dependsOn ...string) {
+	a.components = append(a.components, &entry{component: c, deps: dependsOn})
+}
+
+// Context 应用的根上下文，开始关闭时取消
+func (a *App) Context() context.Context {
+	return a.ctx
+}
+
+// Start 按依赖顺序启动所有组件
+// 任一组件启动失败时，停止已启动的组件并返回错误
+func (a *App) Start() error {
+	ordered, err := a.order()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range ordered {
+		name := e.component.Name()
+		if err := e.component.Start(a.ctx); err != nil {
+			if stopErr := a.Stop(); stopErr != nil {
+				log.Printf("停止已启动的组件失败: %v", stopErr)
+			}
+			return fmt.Errorf("启动 %s 失败: %v", name, err)
+		}
+		a.started = append(a.started, e)
+		log.Printf("组件 %s 已启动", name)
+
+		if ex, ok := e.component.(Exiter); ok {
+			go a.watchExit(ex)
+		}
+	}
+	return nil
+}
+
+// watchExit 组件要求退出时关闭应用
+func (a *App) watchExit(ex Exiter) {
+	select {
+	case err := <-ex.Exit():
+		a.Shutdown(err)
+	case <-a.ctx.Done():
+	}
+}
+
+// Shutdown 请求关闭应用，err 不为空表示异常退出，只有第一次调用生效
+func (a *App) Shutdown(err error) {
+	a.exitOnce.Do(func() {
+		a.exitErr = err
+		close(a.exit)
+	})
+}
+
+// Stop 取消根上下文，并按启动的相反顺序停止已启动的组件
+// 超过关闭期限后仍会依次调用剩余组件的 Stop（ctx 已过期），返回所有错误
+func (a *App) Stop() error {
+	a.cancel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if a.shutdownTimeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), a.shutdownTimeout)
+	}
+	defer cancel()
+
+	var errs []error
+	for i := len(a.started) - 1; i >= 0; i-- {
+		name := a.started[i].component.Name()
+		if err := a.started[i].component.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("停止 %s 失败: %v", name, err))
+			continue
+		}
+		log.Printf("组件 %s 已停止", name)
+	}
+	a.started = nil
+	return errors.Join(errs...)
+}
+
+// Run 启动所有组件，直到收到 SIGINT/SIGTERM 或有组件要求退出，然后停止所有组件
+func (a *App) Run() error {
+	// kill 默认会发送 syscall.SIGTERM 信号
+	// kill -2 发送 syscall.SIGINT 信号，我们常用的Ctrl+C就是触发系统SIGINT信号
+	// kill -9 发送 syscall.SIGKILL 信号，但是不能被捕获，所以不需要添加
+	// 启动完成后再捕获信号，启动期间（如数据库重试连接）收到信号时进程直接退出
+	if err := a.Start(); err != nil {
+		return err
+	}
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case sig := <-quit:
+		log.Printf("收到 %v，正在关闭...", sig)
+	case <-a.exit:
+		log.Println("正在关闭...")
+	}
+
+	return errors.Join(a.exitErr, a.Stop())
+}
+
+// order 按依赖关系对组件排序，依赖不存在、名称重复或循环依赖时返回错误
+func (a *App) order() ([]*entry, error) {
+	byName := make(map[string]*entry, len(a.components))
+	for _, e := range a.components {
+		name := e.component.Name()
+		if _, ok := byName[name]; ok {
+			return nil, fmt.Errorf("组件名称重复: %s", name)
+		}
+		byName[name] = e
+	}
+	for _, e := range a.components {
+		for _, dep := range e.deps {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("组件 %s 依赖的 %s 未注册", e.component.Name(), dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(a.components))
+	ordered := make([]*entry, 0, len(a.components))
+
+	var visit func(e *entry, path []string) error
+	visit = func(e *entry, path []string) error {
+		name := e.component.Name()
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("组件循环依赖: %s", strings.Join(append(path, name), " -> "))
+		}
+		state[name] = visiting
+		for _, dep := range e.deps {
+			if err := visit(byName[dep], append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		ordered = append(ordered, e)
+		return nil
+	}
+
+	for _, e := range a.components {
+		if err := visit(e, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
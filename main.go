@@ -1,63 +1,79 @@
 package main
 
 import (
-	"context"
+	"flag"
+	"fmt"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"strings"
 
 	"golang-web/config"
-	"golang-web/database"
-	"golang-web/routes"
+	"golang-web/security"
 )
 
+// usage 命令行帮助
+const usage = `用法: golang-web <命令> [参数]
+
+命令:
+  serve             启动 HTTP 服务（默认命令）
+  migrate           数据库迁移: up / down / status
+  user              用户管理: create / list / disable / enable / reset-password
+  token             令牌工具: issue / inspect
+  config            配置工具: print / check
+  bootstrap-admin   创建第一个管理员
+
+所有命令都支持 -config 和 -env 参数，使用 golang-web <命令> -h 查看各命令的参数
+`
+
 func main() {
-	// 加载配置
-	cfg := config.LoadConfig()
-	log.Printf("应用启动，环境: %s, 端口: %s", os.Getenv("GO_ENV"), cfg.Server.Port)
+	args := os.Args[1:]
 
-	// 初始化数据库连接
-	if err := database.InitDB(cfg); err != nil {
-		log.Fatalf("数据库连接失败: %v", err)
+	// 不带命令或直接以参数开头时启动服务，兼容 golang-web -env production
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "--help") {
+		runServe(args)
+		return
 	}
-	defer database.CloseDB()
-	log.Println("数据库连接成功")
-
-	// 设置路由
-	router := routes.SetupRoutes(cfg)
 
-	// 创建HTTP服务器
-	srv := &http.Server{
-		Addr:    ":" + cfg.Server.Port,
-		Handler: router,
+	switch args[0] {
+	case "serve":
+		runServe(args[1:])
+	case "migrate":
+		runMigrate(args[1:])
+	case "user":
+		runUser(args[1:])
+	case "token":
+		runToken(args[1:])
+	case "config":
+		runConfig(args[1:])
+	case "bootstrap-admin":
+		runBootstrapAdmin(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n%s", args[0], usage)
+		os.Exit(2)
 	}
+}
+
+// configFlags 注册配置相关的命令行参数
+func configFlags(fs *flag.FlagSet) *config.Options {
+	opts := &config.Options{}
+	fs.StringVar(&opts.File, "config", "", "配置文件路径，默认按运行环境查找 config/config.<env>.yaml")
+	fs.StringVar(&opts.Env, "env", "", "运行环境，默认读取环境变量 GO_ENV")
+	return opts
+}
 
-	// 在goroutine中启动服务器
-	go func() {
-		log.Printf("HTTP服务器启动在端口 %s", cfg.Server.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("服务器启动失败: %v", err)
-		}
-	}()
-
-	// 等待中断信号以优雅地关闭服务器
-	quit := make(chan os.Signal, 1)
-	// kill 默认会发送 syscall.SIGTERM 信号
-	// kill -2 发送 syscall.SIGINT 信号，我们常用的Ctrl+C就是触发系统SIGINT信号
-	// kill -9 发送 syscall.SIGKILL 信号，但是不能被捕获，所以不需要添加
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("正在关闭服务器...")
-
-	// 设置5秒的超时时间
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("服务器强制关闭:", err)
+// loadConfig 加载配置并配置密码哈希算法，失败时终止程序
+func loadConfig(opts config.Options) *config.Config {
+	cfg := config.Load(opts)
+	if err := security.ConfigureHasher(cfg.Password.Hash); err != nil {
+		log.Fatalf("密码哈希配置错误: %v", err)
 	}
+	return cfg
+}
 
-	log.Println("服务器已退出")
+// subcommandUsage 打印二级子命令的用法并以状态码 2 退出
+func subcommandUsage(text string) {
+	fmt.Fprint(os.Stderr, text)
+	os.Exit(2)
 }
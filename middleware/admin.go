@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"golang-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin 管理员权限中间件，需在 AuthMiddleware 之后使用
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireAdmin 检查当前用户是否为有效的管理员，不是时写入响应并中止请求，返回 false
+func requireAdmin(c *gin.Context) bool {
+	userID := c.GetInt("user_id")
+
+	user, err := models.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取用户信息失败",
+			"error":   err.Error(),
+		})
+		c.Abort()
+		return false
+	}
+
+	if user == nil || !user.IsActive() || !user.IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "需要管理员权限",
+		})
+		c.Abort()
+		return false
+	}
+
+	return true
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"golang-web/audit"
 	"golang-web/config"
 	"golang-web/utils"
 
@@ -11,55 +12,91 @@ import (
 )
 
 // AuthMiddleware JWT认证中间件
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+func AuthMiddleware(holder *config.Holder) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从请求头获取Authorization
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "缺少认证令牌",
-			})
-			c.Abort()
+		if !authenticate(c, holder.Get()) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		// 检查Bearer前缀
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "无效的认证格式",
-			})
-			c.Abort()
-			return
-		}
+// authenticate 校验请求的JWT令牌和会话，通过后将用户信息存储到上下文中
+// 校验失败时写入响应并中止请求，返回 false
+func authenticate(c *gin.Context, cfg *config.Config) bool {
+	// 从请求头获取Authorization
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		audit.RecordGin(c, audit.EventTokenRejected, audit.OutcomeFailure, 0, "", "缺少认证令牌")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "缺少认证令牌",
+		})
+		c.Abort()
+		return false
+	}
 
-		tokenString := tokenParts[1]
+	// 检查Bearer前缀
+	tokenParts := strings.Split(authHeader, " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		audit.RecordGin(c, audit.EventTokenRejected, audit.OutcomeFailure, 0, "", "无效的认证格式")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "无效的认证格式",
+		})
+		c.Abort()
+		return false
+	}
 
-		// 验证JWT令牌
-		claims, err := utils.ValidateToken(tokenString, cfg)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "无效的认证令牌",
-				"error":   err.Error(),
-			})
-			c.Abort()
-			return
-		}
+	tokenString := tokenParts[1]
 
-		// 将用户信息存储到上下文中
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
+	// 验证JWT令牌
+	claims, err := utils.ValidateToken(tokenString, cfg)
+	if err != nil {
+		audit.RecordGin(c, audit.EventTokenRejected, audit.OutcomeFailure, 0, "", err.Error())
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "无效的认证令牌",
+			"error":   err.Error(),
+		})
+		c.Abort()
+		return false
+	}
 
-		c.Next()
+	// 检查会话是否已被注销
+	ok, err := checkSession(c, cfg, claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "校验会话失败",
+			"error":   err.Error(),
+		})
+		c.Abort()
+		return false
 	}
+	if !ok {
+		audit.RecordGin(c, audit.EventTokenRejected, audit.OutcomeFailure, claims.UserID, claims.Username, "会话已失效")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "会话已失效，请重新登录",
+		})
+		c.Abort()
+		return false
+	}
+
+	// 将用户信息存储到上下文中
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("session_id", claims.SessionID)
+	c.Set("claims", claims)
+	return true
 }
 
 // OptionalAuthMiddleware 可选的JWT认证中间件（不强制要求认证）
-func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+func OptionalAuthMiddleware(holder *config.Holder) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		cfg := holder.Get()
+
 		// 从请求头获取Authorization
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -86,9 +123,17 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// 会话已注销或校验失败，继续执行但不设置用户信息
+		if ok, err := checkSession(c, cfg, claims); err != nil || !ok {
+			c.Next()
+			return
+		}
+
 		// 将用户信息存储到上下文中
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("session_id", claims.SessionID)
+		c.Set("claims", claims)
 
 		c.Next()
 	}
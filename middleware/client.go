@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"golang-web/audit"
+	"golang-web/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientOrAdmin 服务间接口的认证中间件
+// 携带 HTTP Basic 认证时按 clients 配置校验客户端凭据，并将客户端ID存储到上下文的 client_id 中；
+// 否则要求管理员的JWT令牌（受限令牌需具有 admin 授权范围），便于运维人员直接调用
+func ClientOrAdmin(holder *config.Holder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := holder.Get()
+
+		if id, secret, ok := c.Request.BasicAuth(); ok {
+			if !checkClient(cfg, id, secret) {
+				audit.RecordGin(c, audit.EventClientRejected, audit.OutcomeFailure, 0, "", "客户端 "+id)
+				c.Header("WWW-Authenticate", `Basic realm="golang-web"`)
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    401,
+					"message": "无效的客户端凭据",
+				})
+				c.Abort()
+				return
+			}
+			c.Set("client_id", id)
+			c.Next()
+			return
+		}
+
+		if !authenticate(c, cfg) || !requireScope(c, ScopeAdmin) || !requireAdmin(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkClient 校验客户端ID和密钥，密钥按固定时间比较
+func checkClient(cfg *config.Config, id, secret string) bool {
+	client := cfg.Client(id)
+	if client == nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(client.Secret), []byte(secret)) == 1
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang-web/config"
+	"golang-web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorReauthRequired 需要重新验证密码时响应中的 error 取值（RFC 9470），
+// 客户端收到后应提示用户输入密码，调用 POST /api/v1/auth/reauth 换取新令牌后重试
+const ErrorReauthRequired = "insufficient_user_authentication"
+
+// RequireRecentAuth 近期认证中间件，需在 AuthMiddleware 之后使用
+// 令牌的 auth_time（最近一次输入密码的时间）早于 jwt.reauth_max_age 之前时返回 401 和 ErrorReauthRequired
+// 时限每次请求从 holder 读取，修改配置后立即生效
+func RequireRecentAuth(holder *config.Holder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := c.Get("claims")
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": "缺少认证令牌",
+			})
+			c.Abort()
+			return
+		}
+
+		maxAge := holder.Get().JWT.ReauthMaxAge
+		if time.Since(claims.(*utils.Claims).AuthenticatedAt()) > maxAge {
+			seconds := int(maxAge.Seconds())
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="%s", max_age="%d"`, ErrorReauthRequired, seconds))
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": "该操作需要重新输入密码",
+				"error":   ErrorReauthRequired,
+				"max_age": seconds,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
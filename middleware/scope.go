@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang-web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 接口使用的授权范围，签发受限令牌时从中选择（utils.WithScopes 或 token issue -scope）
+const (
+	ScopeProfileRead = "profile:read" // 读取用户信息
+	ScopeSessions    = "sessions"     // 查看和注销登录会话
+	ScopeAccount     = "account"      // 修改密码等账户操作
+	ScopeAdmin       = "admin"        // 管理员接口（仍要求管理员角色）
+)
+
+// RequireScope 授权范围中间件，需在 AuthMiddleware 之后使用
+// 令牌必须具有全部指定的授权范围，未限制授权范围的令牌（如登录签发的令牌）直接放行
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireScope(c, scopes...) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireScope 检查当前令牌的授权范围，不满足时按 RFC 6750 返回 insufficient_scope 并中止请求，返回 false
+func requireScope(c *gin.Context, scopes ...string) bool {
+	claims, ok := c.Get("claims")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "缺少认证令牌",
+		})
+		c.Abort()
+		return false
+	}
+
+	var missing []string
+	for _, scope := range scopes {
+		if !claims.(*utils.Claims).HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) == 0 {
+		return true
+	}
+
+	c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " ")))
+	c.JSON(http.StatusForbidden, gin.H{
+		"code":    403,
+		"message": "令牌的授权范围不足",
+		"error":   "缺少授权范围: " + strings.Join(missing, " "),
+	})
+	c.Abort()
+	return false
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"golang-web/config"
+	"golang-web/models"
+	"golang-web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// lastTouched 各会话最后一次写入 last_seen 的时间，用于节流
+	lastTouched sync.Map
+	// lastPruned 上次清理 lastTouched 的时间
+	lastPruned   time.Time
+	lastPrunedMu sync.Mutex
+)
+
+// checkSession 检查令牌所属会话是否仍然有效，并按节流间隔更新最后活跃时间
+// 超过 session.idle_timeout 没有请求的会话会被注销
+// 未携带会话ID的令牌（会话管理上线前签发）直接放行
+func checkSession(c *gin.Context, cfg *config.Config, claims *utils.Claims) (bool, error) {
+	if claims.SessionID == "" {
+		return true, nil
+	}
+
+	session, err := models.GetSession(c.Request.Context(), claims.SessionID)
+	if err != nil {
+		return false, err
+	}
+	if session == nil || session.UserID != claims.UserID || session.Expired(cfg.Session) {
+		lastTouched.Delete(claims.SessionID)
+		// 空闲超时的会话在此注销
+		if session != nil && session.UserID == claims.UserID && session.IsActive() {
+			if err := models.RevokeSession(c.Request.Context(), session.UserID, session.ID); err != nil && !errors.Is(err, models.ErrSessionNotFound) {
+				log.Printf("注销空闲会话 %s 失败: %v", session.ID, err)
+			}
+		}
+		return false, nil
+	}
+
+	touchSession(c.Request.Context(), cfg, claims.SessionID, c.ClientIP())
+	return true, nil
+}
+
+// touchSession 节流更新会话最后活跃时间，写入失败只记录日志
+func touchSession(ctx context.Context, cfg *config.Config, sessionID, ip string) {
+	interval := cfg.Session.TouchInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	now := time.Now()
+	if v, ok := lastTouched.Load(sessionID); ok && now.Sub(v.(time.Time)) < interval {
+		return
+	}
+	lastTouched.Store(sessionID, now)
+
+	if err := models.TouchSession(ctx, sessionID, ip); err != nil {
+		log.Printf("更新会话 %s 最后活跃时间失败: %v", sessionID, err)
+	}
+
+	pruneTouched(now, interval)
+}
+
+// pruneTouched 定期清理长时间未活跃的节流记录，避免内存无限增长
+func pruneTouched(now time.Time, interval time.Duration) {
+	lastPrunedMu.Lock()
+	if now.Sub(lastPruned) < 10*interval {
+		lastPrunedMu.Unlock()
+		return
+	}
+	lastPruned = now
+	lastPrunedMu.Unlock()
+
+	lastTouched.Range(func(key, value interface{}) bool {
+		if now.Sub(value.(time.Time)) > 2*interval {
+			lastTouched.Delete(key)
+		}
+		return true
+	})
+}
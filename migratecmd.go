@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"golang-web/database"
+)
+
+// migrateUsage migrate 命令的用法
+const migrateUsage = `用法:
+  golang-web migrate up [-steps N]                执行未执行的迁移，默认全部
+  golang-web migrate down [-steps N] [-force]     回滚最近执行的迁移，默认 1 个
+  golang-web migrate status                       查看迁移状态
+
+回滚基础表（迁移 1）会删除全部用户、会话和审计日志，必须指定 -force
+`
+
+// runMigrate 数据库迁移子命令，不受 database.auto_migrate 影响
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		subcommandUsage(migrateUsage)
+	}
+
+	action := args[0]
+	fs := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	opts := configFlags(fs)
+	var steps *int
+	var force *bool
+	switch action {
+	case "up":
+		steps = fs.Int("steps", 0, "最多执行的迁移个数，0 表示全部")
+	case "down":
+		steps = fs.Int("steps", 1, "回滚的迁移个数")
+		force = fs.Bool("force", false, "允许回滚基础表（删除全部用户、会话和审计日志）")
+	case "status":
+	default:
+		subcommandUsage(migrateUsage)
+	}
+	fs.Parse(args[1:])
+
+	cfg := loadConfig(*opts)
+	if err := database.Open(cfg); err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	defer database.CloseDB()
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		done, err := database.MigrateUp(ctx, *steps)
+		if err != nil {
+			log.Fatalf("迁移失败: %v", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("数据库已是最新版本")
+		}
+	case "down":
+		if *steps <= 0 {
+			log.Fatal("-steps 必须大于 0")
+		}
+		done, err := database.MigrateDown(ctx, *steps, *force)
+		if err != nil {
+			if errors.Is(err, database.ErrDestructiveRollback) {
+				log.Fatalf("回滚失败: %v，确认后使用 -force 重试", err)
+			}
+			log.Fatalf("回滚失败: %v", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		}
+	case "status":
+		states, err := database.MigrationStatus(ctx)
+		if err != nil {
+			log.Fatalf("获取迁移状态失败: %v", err)
+		}
+		printMigrationStatus(states)
+	}
+}
+
+// printMigrationStatus 以表格输出迁移状态
+func printMigrationStatus(states []database.MigrationState) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "版本\t名称\t执行时间")
+	for _, s := range states {
+		applied := "未执行"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		name := s.Name
+		if s.Unknown {
+			name += "（当前程序中不存在）"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, name, applied)
+	}
+	w.Flush()
+}
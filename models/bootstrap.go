@@ -0,0 +1,196 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"golang-web/database"
+)
+
+// ErrAdminExists 已存在管理员，不能再次初始化
+var ErrAdminExists = errors.New("已存在管理员账号，不能重复初始化")
+
+// 早期版本自动创建的默认账号
+const (
+	defaultAdminUsername = "admin"
+	defaultAdminPassword = "admin123"
+)
+
+// BootstrapAdminRequest 初始化管理员请求结构
+type BootstrapAdminRequest struct {
+	SetupToken string `json:"setup_token" binding:"required"`
+	Username   string `json:"username" binding:"required,min=3,max=50"`
+	Password   string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+}
+
+// AdminExists 是否已存在未删除的管理员
+func AdminExists(ctx context.Context) (bool, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	return adminExists(ctx, database.DB)
+}
+
+// adminExists 在指定的连接或事务上检查是否已存在未删除的管理员
+func adminExists(ctx context.Context, db database.Execer) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM t_user WHERE role = ?` + notDeleted
+	if err := db.QueryRowContext(ctx, database.Rebind(query), RoleAdmin).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// BootstrapAdmin 创建第一个管理员，已存在管理员时返回 ErrAdminExists
+// 检查、插入和记录密码历史在同一个可串行化事务中完成，多个实例同时初始化时只有一个成功
+func BootstrapAdmin(ctx context.Context, username, email, password string) (*User, error) {
+	hashedPassword, err := database.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("密码加密失败: %v", err)
+	}
+
+	tctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	user, err := bootstrapAdminTx(tctx, username, email, hashedPassword)
+	if err != nil && !errors.Is(err, ErrAdminExists) {
+		// 并发初始化时落败的事务会因序列化冲突或死锁失败，此时按已存在管理员处理
+		if exists, existsErr := AdminExists(ctx); existsErr == nil && exists {
+			return nil, ErrAdminExists
+		}
+	}
+	return user, err
+}
+
+// bootstrapAdminTx 在可串行化事务中创建管理员
+func bootstrapAdminTx(ctx context.Context, username, email, hashedPassword string) (*User, error) {
+	tx, err := database.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	exists, err := adminExists(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrAdminExists
+	}
+
+	// 已删除的用户名同样不可复用
+	var count int
+	if err := tx.QueryRowContext(ctx, database.Rebind(`SELECT COUNT(*) FROM t_user WHERE username = ?`), username).Scan(&count); err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("用户名已存在")
+	}
+
+	// 初始化创建的管理员没有操作人，created_by 为空
+	currentTime := database.FormatTime(time.Now())
+	query := `INSERT INTO t_user (username, password, email, role, created_by, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	userID, err := database.InsertReturningIDOn(ctx, tx, query,
+		username, hashedPassword, email, RoleAdmin, nullableID(0), currentTime, currentTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录初始密码，用于密码历史检查
+	if err := addPasswordHistory(ctx, tx, int(userID), hashedPassword); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:       int(userID),
+		Username: username,
+		Email:    email,
+		Status:   UserStatusActive,
+		Role:     RoleAdmin,
+	}, nil
+}
+
+// FindDefaultAdmin 查找仍使用默认密码 admin123 的 admin 账号（读主库），不存在时返回 nil
+func FindDefaultAdmin(ctx context.Context) (*User, error) {
+	user, err := GetUserByUsername(database.UsePrimary(ctx), defaultAdminUsername)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if !user.ValidatePassword(defaultAdminPassword) {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// LockDefaultAdmin 禁用使用默认密码的账号，把密码替换为随机值并注销其所有会话
+// 之后必须重置密码并重新启用才能登录
+func LockDefaultAdmin(ctx context.Context, user *User) error {
+	password, err := GeneratePassword(32)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := database.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %v", err)
+	}
+
+	tctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	// 以旧哈希作为条件，避免覆盖并发修改的新密码
+	currentTime := database.FormatTime(time.Now())
+	query := `UPDATE t_user SET status = ?, password = ?, update_time = ? WHERE id = ? AND password = ?` + notDeleted
+	if _, err := database.DB.ExecContext(tctx, database.Rebind(query),
+		UserStatusDisabled, hashedPassword, currentTime, user.ID, user.Password); err != nil {
+		return err
+	}
+	return RevokeUserSessions(ctx, user.ID, "")
+}
+
+// GeneratePassword 生成随机密码，包含大小写字母、数字和特殊字符
+func GeneratePassword(length int) (string, error) {
+	const (
+		lower   = "abcdefghijkmnopqrstuvwxyz"
+		upper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+		digits  = "23456789"
+		symbols = "!@#$%^&*-_=+"
+	)
+	if length < 4 {
+		length = 4
+	}
+
+	// 每类字符至少一个，其余从全部字符中随机选取
+	sets := []string{lower, upper, digits, symbols}
+	all := lower + upper + digits + symbols
+	buf := make([]byte, length)
+	for i := range buf {
+		set := all
+		if i < len(sets) {
+			set = sets[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+		if err != nil {
+			return "", err
+		}
+		buf[i] = set[n.Int64()]
+	}
+
+	// 打乱顺序
+	for i := len(buf) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		buf[i], buf[j.Int64()] = buf[j.Int64()], buf[i]
+	}
+	return string(buf), nil
+}
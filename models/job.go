@@ -0,0 +1,218 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"golang-web/database"
+	"golang-web/utils"
+)
+
+// 定时任务触发方式
+const (
+	JobTriggerSchedule = "schedule" // 按计划执行
+	JobTriggerManual   = "manual"   // 管理员手动触发
+)
+
+// 定时任务执行状态
+const (
+	JobStatusRunning = "running"
+	JobStatusSuccess = "success"
+	JobStatusFailed  = "failed"
+)
+
+// JobRun 定时任务的一次执行记录
+type JobRun struct {
+	ID         int64      `json:"id"`
+	JobName    string     `json:"job_name"`
+	Trigger    string     `json:"trigger"`
+	Owner      string     `json:"owner"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Status     string     `json:"status"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// jobRunColumns 查询执行记录时使用的字段列表，与 scanJobRun 的顺序一致
+const jobRunColumns = `id, job_name, trigger_type, owner, start_time, end_time, status, result, error`
+
+// scanJobRun 将一行查询结果扫描为执行记录
+func scanJobRun(row rowScanner) (*JobRun, error) {
+	var (
+		r          = &JobRun{}
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(&r.ID, &r.JobName, &r.Trigger, &r.Owner, &r.StartedAt, &finishedAt,
+		&r.Status, &r.Result, &r.Error); err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		r.FinishedAt = &finishedAt.Time
+	}
+	return r, nil
+}
+
+// EnsureJobLock 确保任务锁记录存在，多个实例同时插入时忽略重复
+func EnsureJobLock(ctx context.Context, name string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var count int
+	query := `SELECT COUNT(*) FROM t_job_lock WHERE name = ?`
+	if err := database.DB.QueryRowContext(ctx, database.Rebind(query), name).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	insert := `INSERT INTO t_job_lock (name, owner, locked_until) VALUES (?, '', ?)`
+	_, err := database.DB.ExecContext(ctx, database.Rebind(insert), name, "1970-01-01 00:00:00")
+	if err != nil {
+		// 其他实例已经插入
+		if database.DB.QueryRowContext(ctx, database.Rebind(query), name).Scan(&count) == nil && count > 0 {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// AcquireJobLock 尝试获取任务锁，锁在 until 之前由 owner 持有
+// tick 不为零时表示按计划执行，同一计划时间只有一个实例能获取到锁；手动触发时传零值
+// 锁已被持有（或该计划时间已由其他实例执行）时返回 false
+func AcquireJobLock(ctx context.Context, name, owner string, until, tick time.Time) (bool, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	now := database.FormatTime(time.Now())
+	var (
+		result sql.Result
+		err    error
+	)
+	if tick.IsZero() {
+		query := `UPDATE t_job_lock SET owner = ?, locked_until = ? WHERE name = ? AND locked_until <= ?`
+		result, err = database.DB.ExecContext(ctx, database.Rebind(query),
+			owner, database.FormatTime(until), name, now)
+	} else {
+		tickTime := database.FormatTime(tick)
+		query := `UPDATE t_job_lock SET owner = ?, locked_until = ?, last_tick = ?
+			WHERE name = ? AND locked_until <= ? AND (last_tick IS NULL OR last_tick < ?)`
+		result, err = database.DB.ExecContext(ctx, database.Rebind(query),
+			owner, database.FormatTime(until), tickTime, name, now, tickTime)
+	}
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// ReleaseJobLock 释放 owner 持有的任务锁
+func ReleaseJobLock(ctx context.Context, name, owner string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE t_job_lock SET locked_until = ? WHERE name = ? AND owner = ?`
+	_, err := database.DB.ExecContext(ctx, database.Rebind(query),
+		database.FormatTime(time.Now()), name, owner)
+	return err
+}
+
+// CreateJobRun 创建执行中的执行记录
+func CreateJobRun(ctx context.Context, name, trigger, owner string) (*JobRun, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	query := `INSERT INTO t_job_run (job_name, trigger_type, owner, start_time, status) VALUES (?, ?, ?, ?, ?)`
+	id, err := database.InsertReturningID(ctx, query,
+		name, trigger, owner, database.FormatTime(now), JobStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	return &JobRun{
+		ID:        id,
+		JobName:   name,
+		Trigger:   trigger,
+		Owner:     owner,
+		StartedAt: now,
+		Status:    JobStatusRunning,
+	}, nil
+}
+
+// FinishJobRun 记录执行结果
+func FinishJobRun(ctx context.Context, run *JobRun) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	run.FinishedAt = &now
+	query := `UPDATE t_job_run SET end_time = ?, status = ?, result = ?, error = ? WHERE id = ?`
+	_, err := database.DB.ExecContext(ctx, database.Rebind(query),
+		database.FormatTime(now), run.Status, utils.Truncate(run.Result, 255), utils.Truncate(run.Error, 1024), run.ID)
+	return err
+}
+
+// ListJobRuns 获取任务最近的执行记录，按开始时间倒序
+func ListJobRuns(ctx context.Context, name string, limit int) ([]*JobRun, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + jobRunColumns + ` FROM t_job_run WHERE job_name = ? ORDER BY id DESC LIMIT ?`
+	rows, err := database.Reader(ctx).QueryContext(ctx, database.Rebind(query), name, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]*JobRun, 0)
+	for rows.Next() {
+		r, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// LastJobRuns 获取每个任务最近一次的执行记录，按任务名索引
+func LastJobRuns(ctx context.Context) (map[string]*JobRun, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + jobRunColumns + ` FROM t_job_run
+		WHERE id IN (SELECT MAX(id) FROM t_job_run GROUP BY job_name)`
+	rows, err := database.Reader(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make(map[string]*JobRun)
+	for rows.Next() {
+		r, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs[r.JobName] = r
+	}
+	return runs, rows.Err()
+}
+
+// DeleteJobRuns 删除 before 之前开始的执行记录，返回删除的行数
+// 使用调用方的上下文（定时任务的超时），不受 database.query_timeout 限制
+func DeleteJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM t_job_run WHERE start_time < ?`
+	result, err := database.DB.ExecContext(ctx, database.Rebind(query), database.FormatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golang-web/database"
+)
+
+// ChangePasswordRequest 修改密码请求结构
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ResetPasswordRequest 管理员重置密码请求结构
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// PasswordUsedRecently 判断密码是否与用户当前密码或最近 n 次使用过的密码相同
+func PasswordUsedRecently(ctx context.Context, user *User, password string, n int) (bool, error) {
+	if n <= 0 {
+		return false, nil
+	}
+	if user.ValidatePassword(password) {
+		return true, nil
+	}
+
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT password FROM t_password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?`
+	rows, err := database.DB.QueryContext(ctx, database.Rebind(query), user.ID, n)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hashed string
+		if err := rows.Scan(&hashed); err != nil {
+			return false, err
+		}
+		if database.CheckPassword(password, hashed) == nil {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// UpdatePassword 更新用户密码并记录密码历史，operatorID 为操作人
+func UpdatePassword(ctx context.Context, userID int, password string, operatorID int) error {
+	hashedPassword, err := database.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %v", err)
+	}
+
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	currentTime := database.FormatTime(time.Now())
+	query := `UPDATE t_user SET password = ?, updated_by = ?, update_time = ? WHERE id = ?` + notDeleted
+	result, err := tx.ExecContext(ctx, database.Rebind(query), hashedPassword, nullableID(operatorID), currentTime, userID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	if err := addPasswordHistory(ctx, tx, userID, hashedPassword); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RehashPassword 使用当前配置的算法重新生成密码哈希（登录验证通过后调用）
+// 只替换哈希，不记录密码历史
+func RehashPassword(ctx context.Context, user *User, password string) error {
+	hashedPassword, err := database.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	// 以旧哈希作为条件，避免覆盖并发修改的新密码
+	query := `UPDATE t_user SET password = ? WHERE id = ? AND password = ?`
+	if _, err := database.DB.ExecContext(ctx, database.Rebind(query), hashedPassword, user.ID, user.Password); err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	return nil
+}
+
+// execer 兼容 *sql.DB 和 *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// addPasswordHistory 记录一条密码历史
+func addPasswordHistory(ctx context.Context, db execer, userID int, hashedPassword string) error {
+	query := `INSERT INTO t_password_history (user_id, password, create_time) VALUES (?, ?, ?)`
+	_, err := db.ExecContext(ctx, database.Rebind(query), userID, hashedPassword, database.FormatTime(time.Now()))
+	return err
+}
@@ -0,0 +1,230 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"golang-web/config"
+	"golang-web/database"
+)
+
+// ErrSessionNotFound 会话不存在
+var ErrSessionNotFound = errors.New("会话不存在")
+
+// Session 登录会话（一个已签发令牌所在的设备）
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int        `json:"user_id"`
+	Device     string     `json:"device"`
+	IP         string     `json:"ip"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	LastIP     string     `json:"last_ip"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Current    bool       `json:"current"` // 是否为当前请求所用的会话
+}
+
+// sessionColumns 查询会话时使用的字段列表，与 scanSession 的顺序一致
+const sessionColumns = `id, user_id, device, ip, user_agent, create_time, last_seen, last_ip, expire_time, revoked_at`
+
+// scanSession 将一行查询结果扫描为会话
+func scanSession(row rowScanner) (*Session, error) {
+	var (
+		s         = &Session{}
+		revokedAt sql.NullTime
+	)
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.Device,
+		&s.IP,
+		&s.UserAgent,
+		&s.CreatedAt,
+		&s.LastSeenAt,
+		&s.LastIP,
+		&s.ExpiresAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		s.RevokedAt = &revokedAt.Time
+	}
+	return s, nil
+}
+
+// IsActive 会话是否有效（未注销且未过期）
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// Idle 会话是否超过 session.idle_timeout 没有请求
+func (s *Session) Idle(cfg config.SessionConfig) bool {
+	return cfg.IdleTimeout > 0 && time.Since(s.LastSeenAt) > cfg.IdleTimeout
+}
+
+// Expired 会话是否已失效：已注销、已过期或已空闲超时
+func (s *Session) Expired(cfg config.SessionConfig) bool {
+	return !s.IsActive() || s.Idle(cfg)
+}
+
+// CreateSession 创建登录会话
+func CreateSession(ctx context.Context, userID int, device, ip, userAgent string, expiresAt time.Time) (*Session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	currentTime := database.FormatTime(now)
+	query := `INSERT INTO t_session (id, user_id, device, ip, user_agent, create_time, last_seen, last_ip, expire_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = database.DB.ExecContext(ctx, database.Rebind(query),
+		id, userID, device, ip, userAgent, currentTime, currentTime, ip, database.FormatTime(expiresAt))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		ID:         id,
+		UserID:     userID,
+		Device:     device,
+		IP:         ip,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		LastIP:     ip,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// GetSession 根据ID获取会话
+// 会话状态关系到令牌能否继续使用，始终读主库
+func GetSession(ctx context.Context, id string) (*Session, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + sessionColumns + ` FROM t_session WHERE id = ?`
+	s, err := scanSession(database.DB.QueryRowContext(ctx, database.Rebind(query), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 会话不存在
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListActiveSessions 获取用户所有有效会话（不含空闲超时的会话），按最后活跃时间倒序
+func ListActiveSessions(ctx context.Context, userID int, cfg config.SessionConfig) ([]*Session, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + sessionColumns + ` FROM t_session
+		WHERE user_id = ? AND revoked_at IS NULL AND expire_time > ?
+		ORDER BY last_seen DESC`
+	rows, err := database.DB.QueryContext(ctx, database.Rebind(query),
+		userID, database.FormatTime(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*Session, 0)
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		if s.Expired(cfg) {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// TouchSession 更新会话最后活跃时间和IP
+func TouchSession(ctx context.Context, id, ip string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE t_session SET last_seen = ?, last_ip = ? WHERE id = ?`
+	_, err := database.DB.ExecContext(ctx, database.Rebind(query),
+		database.FormatTime(time.Now()), ip, id)
+	return err
+}
+
+// ExtendSession 延长会话过期时间（刷新令牌时调用）
+func ExtendSession(ctx context.Context, id string, expiresAt time.Time) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE t_session SET expire_time = ? WHERE id = ? AND revoked_at IS NULL`
+	_, err := database.DB.ExecContext(ctx, database.Rebind(query),
+		database.FormatTime(expiresAt), id)
+	return err
+}
+
+// RevokeSession 注销用户的指定会话，会话不存在或不属于该用户时返回 ErrSessionNotFound
+func RevokeSession(ctx context.Context, userID int, id string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE t_session SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+	result, err := database.DB.ExecContext(ctx, database.Rebind(query),
+		database.FormatTime(time.Now()), id, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions 注销用户除 exceptID 外的所有会话，exceptID 为空时注销全部
+func RevokeUserSessions(ctx context.Context, userID int, exceptID string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE t_session SET revoked_at = ? WHERE user_id = ? AND id <> ? AND revoked_at IS NULL`
+	_, err := database.DB.ExecContext(ctx, database.Rebind(query),
+		database.FormatTime(time.Now()), userID, exceptID)
+	return err
+}
+
+// DeleteStaleSessions 删除 before 之前已过期或已注销的会话，返回删除的行数
+// 使用调用方的上下文（定时任务的超时），不受 database.query_timeout 限制
+func DeleteStaleSessions(ctx context.Context, before time.Time) (int64, error) {
+	t := database.FormatTime(before)
+	query := `DELETE FROM t_session WHERE expire_time < ? OR revoked_at < ?`
+	result, err := database.DB.ExecContext(ctx, database.Rebind(query), t, t)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// newSessionID 生成随机会话ID
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
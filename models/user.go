@@ -1,27 +1,60 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"golang-web/database"
 )
 
+// 用户状态
+const (
+	UserStatusActive   = "active"   // 正常
+	UserStatusDisabled = "disabled" // 已禁用
+	UserStatusDeleted  = "deleted"  // 已删除（软删除）
+)
+
+// 用户角色
+const (
+	RoleUser  = "user"  // 普通用户
+	RoleAdmin = "admin" // 管理员
+)
+
 // User 用户模型
 type User struct {
-	ID        int       `json:"id" db:"id"`
-	Username  string    `json:"username" db:"username"`
-	Password  string    `json:"-" db:"password"` // 密码不返回给前端
-	Email     string    `json:"email" db:"email"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID          int        `json:"id" db:"id"`
+	Username    string     `json:"username" db:"username"`
+	Password    string     `json:"-" db:"password"` // 密码不返回给前端
+	Email       string     `json:"email" db:"email"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	Status      string     `json:"status" db:"status"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	LastLoginIP string     `json:"last_login_ip" db:"last_login_ip"`
+	CreatedBy   *int       `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy   *int       `json:"updated_by,omitempty" db:"updated_by"`
+	Role        string     `json:"role" db:"role"`
 }
 
 // LoginRequest 登录请求结构
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Device   string `json:"device" binding:"max=100"` // 可选，设备名称，为空时根据 User-Agent 识别
+}
+
+// ReauthRequest 重新验证密码请求结构
+type ReauthRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangeEmailRequest 修改邮箱请求结构
+type ChangeEmailRequest struct {
+	Email string `json:"email" binding:"required,email,max=32"`
 }
 
 // LoginResponse 登录响应结构
@@ -33,48 +66,93 @@ type LoginResponse struct {
 // RegisterRequest 注册请求结构
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"` // 由密码安全策略校验
 	Email    string `json:"email" binding:"required,email"`
 }
 
-// GetUserByUsername 根据用户名获取用户
-func GetUserByUsername(username string) (*User, error) {
-	user := &User{}
-	query := `SELECT id, username, password, email, create_time, update_time FROM t_user WHERE username = ?`
+// ErrUserNotFound 用户不存在
+var ErrUserNotFound = errors.New("用户不存在")
 
-	err := database.DB.QueryRow(query, username).Scan(
+// userColumns 查询用户时使用的字段列表，与 scanUser 的顺序一致
+const userColumns = `id, username, password, email, create_time, update_time,
+	status, deleted_at, last_login_at, last_login_ip, created_by, updated_by, role`
+
+// notDeleted 排除已删除用户的查询条件
+const notDeleted = ` AND status <> 'deleted'`
+
+// rowScanner 兼容 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser 将一行查询结果扫描为用户
+func scanUser(row rowScanner) (*User, error) {
+	var (
+		user        = &User{}
+		deletedAt   sql.NullTime
+		lastLoginAt sql.NullTime
+		lastLoginIP sql.NullString
+		createdBy   sql.NullInt64
+		updatedBy   sql.NullInt64
+	)
+	err := row.Scan(
 		&user.ID,
 		&user.Username,
 		&user.Password,
 		&user.Email,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Status,
+		&deletedAt,
+		&lastLoginAt,
+		&lastLoginIP,
+		&createdBy,
+		&updatedBy,
+		&user.Role,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // 用户不存在
-		}
 		return nil, err
 	}
 
+	if deletedAt.Valid {
+		user.DeletedAt = &deletedAt.Time
+	}
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
+	user.LastLoginIP = lastLoginIP.String
+	if createdBy.Valid {
+		id := int(createdBy.Int64)
+		user.CreatedBy = &id
+	}
+	if updatedBy.Valid {
+		id := int(updatedBy.Int64)
+		user.UpdatedBy = &id
+	}
 	return user, nil
 }
 
-// GetUserByID 根据用户ID获取用户
-func GetUserByID(userID int) (*User, error) {
-	user := &User{}
-	query := `SELECT id, username, password, email, create_time, update_time FROM t_user WHERE id = ?`
+// IsActive 用户是否处于正常状态
+func (u *User) IsActive() bool {
+	return u.Status == UserStatusActive
+}
 
-	err := database.DB.QueryRow(query, userID).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Password,
-		&user.Email,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+// IsAdmin 用户是否为管理员
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// getUser 按条件查询单个用户，includeDeleted 为 false 时排除已删除用户
+func getUser(ctx context.Context, where string, arg interface{}, includeDeleted bool) (*User, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + userColumns + ` FROM t_user WHERE ` + where
+	if !includeDeleted {
+		query += notDeleted
+	}
 
+	user, err := scanUser(database.Reader(ctx).QueryRowContext(ctx, database.Rebind(query), arg))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // 用户不存在
@@ -85,10 +163,51 @@ func GetUserByID(userID int) (*User, error) {
 	return user, nil
 }
 
+// GetUserByUsername 根据用户名获取用户（不含已删除用户）
+// 默认从从库读取，需要读取刚写入的数据时使用 database.UsePrimary(ctx)
+func GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	return getUser(ctx, "username = ?", username, false)
+}
+
+// GetUserByID 根据用户ID获取用户（不含已删除用户）
+func GetUserByID(ctx context.Context, userID int) (*User, error) {
+	return getUser(ctx, "id = ?", userID, false)
+}
+
+// GetUserByIDUnscoped 根据用户ID获取用户（包含已删除用户）
+func GetUserByIDUnscoped(ctx context.Context, userID int) (*User, error) {
+	return getUser(ctx, "id = ?", userID, true)
+}
+
+// ListUsers 分页获取用户列表（不含已删除用户）
+func ListUsers(ctx context.Context, offset, limit int) ([]*User, error) {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + userColumns + ` FROM t_user WHERE 1 = 1` + notDeleted + ` ORDER BY id LIMIT ? OFFSET ?`
+
+	rows, err := database.Reader(ctx).QueryContext(ctx, database.Rebind(query), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*User, 0, limit)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
 // CreateUser 创建新用户
-func CreateUser(req *RegisterRequest) (*User, error) {
-	// 检查用户名是否已存在
-	existingUser, err := GetUserByUsername(req.Username)
+func CreateUser(ctx context.Context, req *RegisterRequest) (*User, error) {
+	// 检查用户名是否已存在（写前检查必须读主库，已删除的用户名同样不可复用）
+	existingUser, err := getUser(database.UsePrimary(ctx), "username = ?", req.Username, true)
 	if err != nil {
 		return nil, err
 	}
@@ -104,33 +223,135 @@ func CreateUser(req *RegisterRequest) (*User, error) {
 	}
 
 	// 获取当前时间
-	currentTime := time.Now().Format("2006-01-02 15:04:05")
+	currentTime := database.FormatTime(time.Now())
 
-	// 创建用户
-	query := `INSERT INTO t_user (username, password, email, create_time, update_time) VALUES (?, ?, ?, ?, ?)`
-	result, err := database.DB.Exec(query, req.Username, hashedPassword, req.Email, currentTime, currentTime)
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	// 创建用户和记录初始密码在同一个事务中完成，失败时不留下没有密码历史的用户
+	tx, err := database.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	userID, err := result.LastInsertId()
+	query := `INSERT INTO t_user (username, password, email, create_time, update_time) VALUES (?, ?, ?, ?, ?)`
+	userID, err := database.InsertReturningIDOn(ctx, tx, query, req.Username, hashedPassword, req.Email, currentTime, currentTime)
 	if err != nil {
 		return nil, err
 	}
 
+	// 记录初始密码，用于密码历史检查
+	if err := addPasswordHistory(ctx, tx, int(userID), hashedPassword); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	// 返回新创建的用户
 	user := &User{
 		ID:       int(userID),
 		Username: req.Username,
 		Email:    req.Email,
+		Status:   UserStatusActive,
+		Role:     RoleUser,
 	}
 
 	return user, nil
 }
 
+// UpdateLastLogin 更新用户最后登录时间和IP
+func UpdateLastLogin(ctx context.Context, userID int, ip string) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	currentTime := database.FormatTime(time.Now())
+	query := `UPDATE t_user SET last_login_at = ?, last_login_ip = ? WHERE id = ?`
+	_, err := database.DB.ExecContext(ctx, database.Rebind(query), currentTime, ip, userID)
+	return err
+}
+
+// SetUserStatus 设置用户状态（active/disabled），operatorID 为操作人
+func SetUserStatus(ctx context.Context, userID int, status string, operatorID int) error {
+	if status != UserStatusActive && status != UserStatusDisabled {
+		return fmt.Errorf("无效的用户状态: %s", status)
+	}
+
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	currentTime := database.FormatTime(time.Now())
+	query := `UPDATE t_user SET status = ?, updated_by = ?, update_time = ? WHERE id = ?` + notDeleted
+	return execAffectingUser(ctx, query, status, nullableID(operatorID), currentTime, userID)
+}
+
+// SetUserRole 设置用户角色（user/admin），operatorID 为操作人
+func SetUserRole(ctx context.Context, userID int, role string, operatorID int) error {
+	if role != RoleUser && role != RoleAdmin {
+		return fmt.Errorf("无效的用户角色: %s", role)
+	}
+
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	currentTime := database.FormatTime(time.Now())
+	query := `UPDATE t_user SET role = ?, updated_by = ?, update_time = ? WHERE id = ?` + notDeleted
+	return execAffectingUser(ctx, query, role, nullableID(operatorID), currentTime, userID)
+}
+
+// UpdateEmail 修改用户邮箱，operatorID 为操作人
+func UpdateEmail(ctx context.Context, userID int, email string, operatorID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	currentTime := database.FormatTime(time.Now())
+	query := `UPDATE t_user SET email = ?, updated_by = ?, update_time = ? WHERE id = ?` + notDeleted
+	return execAffectingUser(ctx, query, email, nullableID(operatorID), currentTime, userID)
+}
+
+// SoftDeleteUser 软删除用户，operatorID 为操作人
+func SoftDeleteUser(ctx context.Context, userID int, operatorID int) error {
+	ctx, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	currentTime := database.FormatTime(time.Now())
+	query := `UPDATE t_user SET status = 'deleted', deleted_at = ?, updated_by = ?, update_time = ? WHERE id = ?` + notDeleted
+	return execAffectingUser(ctx, query, currentTime, nullableID(operatorID), currentTime, userID)
+}
+
+// execAffectingUser 执行更新语句，未影响任何行时返回用户不存在
+func execAffectingUser(ctx context.Context, query string, args ...interface{}) error {
+	result, err := database.DB.ExecContext(ctx, database.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// nullableID 将 0 转换为 NULL，用于 created_by/updated_by
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
 // ValidatePassword 验证用户密码
 func (u *User) ValidatePassword(password string) bool {
-	// 使用 bcrypt 进行安全的密码比较
+	// 按哈希格式自动选择算法进行安全的密码比较
 	err := database.CheckPassword(password, u.Password)
 	return err == nil
 }
+
+// NeedsRehash 密码哈希是否由过时的算法或参数生成
+func (u *User) NeedsRehash() bool {
+	return database.NeedsRehash(u.Password)
+}
@@ -4,12 +4,16 @@ import (
 	"golang-web/config"
 	"golang-web/handlers"
 	"golang-web/middleware"
+	"golang-web/scheduler"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes 设置路由
-func SetupRoutes(cfg *config.Config) *gin.Engine {
+// 处理器和中间件通过 holder 读取配置，热加载后立即使用新配置；sched 用于定时任务管理接口
+func SetupRoutes(holder *config.Holder, sched *scheduler.Scheduler) *gin.Engine {
+	cfg := holder.Get()
+
 	// 设置Gin模式
 	gin.SetMode(cfg.Server.Mode)
 
@@ -17,36 +21,76 @@ func SetupRoutes(cfg *config.Config) *gin.Engine {
 	r := gin.Default()
 
 	// 添加中间件
-	r.Use(gin.Logger())   // 日志中间件
-	r.Use(gin.Recovery()) // 恢复中间件
+	r.Use(gin.Logger())                                  // 日志中间件
+	r.Use(gin.Recovery())                                // 恢复中间件
+	r.Use(middleware.RequestID())                        // 请求ID中间件
+	r.Use(middleware.BodyLimit(cfg.Server.MaxBodyBytes)) // 请求体大小限制
 
 	// 创建处理器
-	authHandler := handlers.NewAuthHandler(cfg)
+	authHandler := handlers.NewAuthHandler(holder)
+	auditHandler := handlers.NewAuditHandler()
+	sessionHandler := handlers.NewSessionHandler(holder)
+	adminHandler := handlers.NewAdminHandler(holder)
+	jobHandler := handlers.NewJobHandler(sched)
+	tokenHandler := handlers.NewTokenHandler(holder)
+	accountHandler := handlers.NewAccountHandler()
 
 	// API路由组
 	api := r.Group("/api/v1")
 	{
-		// 认证相关路由（无需认证）
+		// 认证相关路由（reauth 外无需认证）
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", authHandler.Login)       // 用户登录
-			auth.POST("/register", authHandler.Register) // 用户注册
+			auth.POST("/login", authHandler.Login)                                      // 用户登录
+			auth.POST("/register", authHandler.Register)                                // 用户注册
+			auth.POST("/reauth", middleware.AuthMiddleware(holder), authHandler.Reauth) // 重新验证密码，换取用于敏感操作的令牌
+		}
+
+		// 令牌内省（客户端凭据或管理员令牌）
+		api.POST("/token/introspect", middleware.ClientOrAdmin(holder), tokenHandler.Introspect)
+
+		// 首次初始化（仅在配置开启时注册）
+		if cfg.Setup.Enabled {
+			setupHandler := handlers.NewSetupHandler(holder)
+			api.POST("/setup", setupHandler.Setup) // 创建第一个管理员
 		}
 
 		// 需要认证的路由
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware(cfg))
+		protected.Use(middleware.AuthMiddleware(holder))
 		{
 			// 用户相关
 			user := protected.Group("/user")
 			{
-				user.GET("/profile", authHandler.GetProfile) // 获取用户信息
+				user.GET("/profile", middleware.RequireScope(middleware.ScopeProfileRead), authHandler.GetProfile)            // 获取用户信息
+				user.GET("/sessions", middleware.RequireScope(middleware.ScopeSessions), sessionHandler.ListSessions)         // 获取登录会话列表
+				user.DELETE("/sessions/:id", middleware.RequireScope(middleware.ScopeSessions), sessionHandler.RevokeSession) // 注销指定会话
+			}
+
+			// 敏感操作，要求近期输入过密码
+			account := protected.Group("/user")
+			account.Use(middleware.RequireScope(middleware.ScopeAccount), middleware.RequireRecentAuth(holder))
+			{
+				account.PUT("/password", authHandler.ChangePassword) // 修改密码
+				account.PUT("/email", accountHandler.ChangeEmail)    // 修改邮箱
+				account.DELETE("", accountHandler.DeleteAccount)     // 注销账号
 			}
 
 			// 令牌相关
 			token := protected.Group("/token")
 			{
-				token.POST("/refresh", authHandler.RefreshToken) // 刷新令牌
+				token.POST("/refresh", authHandler.RefreshToken) // 刷新令牌（新令牌沿用原授权范围）
+			}
+
+			// 管理员接口
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireScope(middleware.ScopeAdmin), middleware.RequireAdmin())
+			{
+				admin.GET("/audit", auditHandler.ListEvents)                  // 查询审计日志
+				admin.POST("/users/:id/password", adminHandler.ResetPassword) // 重置用户密码
+				admin.GET("/jobs", jobHandler.ListJobs)                       // 定时任务列表
+				admin.POST("/jobs/:name/run", jobHandler.RunJob)              // 立即执行定时任务
+				admin.GET("/jobs/:name/runs", jobHandler.ListRuns)            // 定时任务执行记录
 			}
 		}
 	}
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang-web/audit"
+	"golang-web/config"
+	"golang-web/models"
+)
+
+// RegisterMaintenanceJobs 注册内置的数据清理任务
+func RegisterMaintenanceJobs(s *Scheduler) error {
+	jobs := []Job{
+		{
+			Name:        "session_cleanup",
+			Description: "删除已过期或已注销超过保留时长的登录会话",
+			Defaults:    config.JobConfig{Schedule: "0 * * * *", Timeout: 5 * time.Minute, Retention: 7 * 24 * time.Hour},
+			Run:         cleanup("会话", models.DeleteStaleSessions),
+		},
+		{
+			Name:        "audit_log_cleanup",
+			Description: "删除超过保留时长的审计日志（仅数据库存储）",
+			Defaults:    config.JobConfig{Schedule: "30 3 * * *", Timeout: 30 * time.Minute, Retention: 90 * 24 * time.Hour},
+			Run:         cleanup("审计日志", audit.Purge),
+		},
+		{
+			Name:        "job_run_cleanup",
+			Description: "删除超过保留时长的定时任务执行历史",
+			Defaults:    config.JobConfig{Schedule: "0 4 * * *", Timeout: 5 * time.Minute, Retention: 30 * 24 * time.Hour},
+			Run:         cleanup("执行历史", models.DeleteJobRuns),
+		},
+	}
+	for _, j := range jobs {
+		if err := s.Register(j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanup 创建删除保留时长之前数据的任务函数
+func cleanup(what string, purge func(ctx context.Context, before time.Time) (int64, error)) Func {
+	return func(ctx context.Context, cfg config.JobConfig) (string, error) {
+		n, err := purge(ctx, time.Now().Add(-cfg.Retention))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("删除%s %d 条", what, n), nil
+	}
+}
@@ -0,0 +1,333 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-web/config"
+	"golang-web/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrJobNotFound 任务不存在
+	ErrJobNotFound = errors.New("任务不存在")
+	// ErrJobRunning 任务正在当前实例或其他实例上执行
+	ErrJobRunning = errors.New("任务正在执行")
+	// ErrStopped 调度器未启动或已停止
+	ErrStopped = errors.New("调度器未启动或已停止")
+)
+
+// defaultTimeout 任务未设置超时时间时的默认值
+const defaultTimeout = 10 * time.Minute
+
+// Func 任务函数，cfg 为合并配置后的任务参数，返回的结果摘要（如删除的行数）记录在执行历史中
+type Func func(ctx context.Context, cfg config.JobConfig) (string, error)
+
+// Job 定时任务定义
+type Job struct {
+	Name        string
+	Description string
+	Defaults    config.JobConfig // 默认的计划、超时和保留时长，可被 scheduler.jobs.<name> 覆盖
+	Run         Func
+}
+
+// JobInfo 任务状态，供管理接口展示
+type JobInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Schedule    string         `json:"schedule"`
+	Enabled     bool           `json:"enabled"` // 是否按计划执行
+	Timeout     string         `json:"timeout"`
+	NextRun     *time.Time     `json:"next_run,omitempty"`
+	Running     bool           `json:"running"` // 当前实例是否正在执行
+	LastRun     *models.JobRun `json:"last_run,omitempty"`
+}
+
+// job 已注册的任务
+type job struct {
+	Job
+	cfg      config.JobConfig // 合并配置后的参数
+	schedule cron.Schedule
+	entryID  cron.EntryID
+	running  atomic.Bool
+}
+
+// Scheduler 进程内的定时任务调度器
+// 多个实例同时运行时通过 t_job_lock 表保证同一任务同一时间只在一个实例上执行，
+// 按计划执行时同一计划时间只执行一次；每次执行记录在 t_job_run 表
+type Scheduler struct {
+	cfg    config.SchedulerConfig
+	owner  string
+	cron   *cron.Cron
+	jobs   []*job
+	byName map[string]*job
+
+	mu      sync.Mutex
+	ctx     context.Context
+	started bool
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// New 创建调度器
+func New(cfg config.SchedulerConfig) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		cfg:    cfg,
+		owner:  fmt.Sprintf("%s:%d", host, os.Getpid()),
+		cron:   cron.New(),
+		byName: make(map[string]*job),
+	}
+}
+
+// Register 注册任务，scheduler.jobs.<name> 中设置的字段覆盖任务的默认值
+func (s *Scheduler) Register(j Job) error {
+	if j.Name == "" || j.Run == nil {
+		return errors.New("任务名称和任务函数不能为空")
+	}
+	if _, ok := s.byName[j.Name]; ok {
+		return fmt.Errorf("任务 %s 重复注册", j.Name)
+	}
+
+	cfg := j.Defaults
+	if o, ok := s.cfg.Jobs[j.Name]; ok {
+		if o.Schedule != "" {
+			cfg.Schedule = o.Schedule
+		}
+		if o.Timeout > 0 {
+			cfg.Timeout = o.Timeout
+		}
+		if o.Retention > 0 {
+			cfg.Retention = o.Retention
+		}
+		cfg.Disabled = cfg.Disabled || o.Disabled
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	schedule, err := cron.ParseStandard(cfg.Schedule)
+	if err != nil {
+		return fmt.Errorf("任务 %s 的计划 %q 无效: %v", j.Name, cfg.Schedule, err)
+	}
+
+	registered := &job{Job: j, cfg: cfg, schedule: schedule}
+	s.jobs = append(s.jobs, registered)
+	s.byName[j.Name] = registered
+	return nil
+}
+
+// Name 组件名称
+func (s *Scheduler) Name() string {
+	return "scheduler"
+}
+
+// Start 初始化任务锁并开始按计划执行任务，ctx 取消时正在执行的任务随之取消
+func (s *Scheduler) Start(ctx context.Context) error {
+	for name := range s.cfg.Jobs {
+		if _, ok := s.byName[name]; !ok {
+			log.Printf("scheduler.jobs.%s 没有对应的任务，已忽略", name)
+		}
+	}
+	for _, j := range s.jobs {
+		if err := models.EnsureJobLock(ctx, j.Name); err != nil {
+			return fmt.Errorf("初始化任务锁 %s 失败: %v", j.Name, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.ctx = ctx
+	s.started = true
+	s.mu.Unlock()
+
+	if !s.cfg.Enabled {
+		log.Println("定时任务未按计划执行（scheduler.enabled 为 false），仍可通过管理接口手动触发")
+		return nil
+	}
+	for _, j := range s.jobs {
+		if j.cfg.Disabled {
+			continue
+		}
+		j := j
+		j.entryID = s.cron.Schedule(j.schedule, cron.FuncJob(func() { s.runScheduled(j) }))
+	}
+	s.cron.Start()
+	log.Printf("定时任务调度器已启动，实例: %s", s.owner)
+	return nil
+}
+
+// Stop 停止调度，等待正在执行的任务结束或 ctx 超时
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.cron.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待任务结束超时: %v", ctx.Err())
+	}
+}
+
+// Trigger 立即在后台执行任务（不受 disabled 和 scheduler.enabled 限制），返回执行记录
+// 任务正在执行时返回 ErrJobRunning
+func (s *Scheduler) Trigger(name string) (*models.JobRun, error) {
+	j, ok := s.byName[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	run, err := s.begin(j, models.JobTriggerManual, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	go s.execute(j, run)
+	return run, nil
+}
+
+// Jobs 列出所有任务及其最近一次执行记录
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobInfo, error) {
+	lastRuns, err := models.LastJobRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		info := JobInfo{
+			Name:        j.Name,
+			Description: j.Description,
+			Schedule:    j.cfg.Schedule,
+			Enabled:     s.cfg.Enabled && !j.cfg.Disabled,
+			Timeout:     j.cfg.Timeout.String(),
+			Running:     j.running.Load(),
+			LastRun:     lastRuns[j.Name],
+		}
+		if j.entryID != 0 {
+			if next := s.cron.Entry(j.entryID).Next; !next.IsZero() {
+				info.NextRun = &next
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Runs 获取任务最近的执行记录
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]*models.JobRun, error) {
+	if _, ok := s.byName[name]; !ok {
+		return nil, ErrJobNotFound
+	}
+	return models.ListJobRuns(ctx, name, limit)
+}
+
+// runScheduled 按计划执行任务，以计划时间区分各次执行，其他实例已执行或正在执行时跳过
+func (s *Scheduler) runScheduled(j *job) {
+	tick := s.cron.Entry(j.entryID).Prev
+	run, err := s.begin(j, models.JobTriggerSchedule, tick)
+	if err != nil {
+		if !errors.Is(err, ErrJobRunning) && !errors.Is(err, ErrStopped) {
+			log.Printf("任务 %s 未执行: %v", j.Name, err)
+		}
+		return
+	}
+	s.execute(j, run)
+}
+
+// begin 获取任务锁并创建执行记录，成功后必须调用 execute
+func (s *Scheduler) begin(j *job, trigger string, tick time.Time) (*models.JobRun, error) {
+	s.mu.Lock()
+	if !s.started || s.stopped {
+		s.mu.Unlock()
+		return nil, ErrStopped
+	}
+	ctx := s.ctx
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	if !j.running.CompareAndSwap(false, true) {
+		s.wg.Done()
+		return nil, ErrJobRunning
+	}
+
+	run, err := s.acquire(ctx, j, trigger, tick)
+	if err != nil {
+		j.running.Store(false)
+		s.wg.Done()
+		return nil, err
+	}
+	return run, nil
+}
+
+// acquire 获取任务锁（租期为任务超时时间）并创建执行记录
+func (s *Scheduler) acquire(ctx context.Context, j *job, trigger string, tick time.Time) (*models.JobRun, error) {
+	ok, err := models.AcquireJobLock(ctx, j.Name, s.owner, time.Now().Add(j.cfg.Timeout), tick)
+	if err != nil {
+		return nil, fmt.Errorf("获取任务锁失败: %v", err)
+	}
+	if !ok {
+		return nil, ErrJobRunning
+	}
+
+	run, err := models.CreateJobRun(ctx, j.Name, trigger, s.owner)
+	if err != nil {
+		if err := models.ReleaseJobLock(context.WithoutCancel(ctx), j.Name, s.owner); err != nil {
+			log.Printf("释放任务锁 %s 失败: %v", j.Name, err)
+		}
+		return nil, fmt.Errorf("创建执行记录失败: %v", err)
+	}
+	return run, nil
+}
+
+// execute 执行任务，记录结果并释放任务锁
+func (s *Scheduler) execute(j *job, run *models.JobRun) {
+	defer s.wg.Done()
+	defer j.running.Store(false)
+
+	ctx, cancel := context.WithTimeout(s.ctx, j.cfg.Timeout)
+	defer cancel()
+
+	result, err := call(ctx, j)
+	run.Result = result
+	if err != nil {
+		run.Status = models.JobStatusFailed
+		run.Error = err.Error()
+		log.Printf("任务 %s 执行失败: %v", j.Name, err)
+	} else {
+		run.Status = models.JobStatusSuccess
+		log.Printf("任务 %s 执行完成: %s", j.Name, result)
+	}
+
+	// 应用关闭导致任务取消时仍要记录结果并释放锁
+	bg := context.WithoutCancel(s.ctx)
+	if err := models.FinishJobRun(bg, run); err != nil {
+		log.Printf("记录任务 %s 执行结果失败: %v", j.Name, err)
+	}
+	if err := models.ReleaseJobLock(bg, j.Name, s.owner); err != nil {
+		log.Printf("释放任务锁 %s 失败: %v", j.Name, err)
+	}
+}
+
+// call 调用任务函数，任务 panic 时转换为错误
+func call(ctx context.Context, j *job) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("任务异常: %v", r)
+		}
+	}()
+	return j.Run(ctx, j.cfg)
+}
@@ -21,10 +21,7 @@ CREATE TABLE IF NOT EXISTS users (
     INDEX idx_email (email)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表';
 
--- 插入默认用户（密码: admin123）
-INSERT INTO users (username, password, email) VALUES 
-('admin', 'admin123', 'admin@example.com')
-ON DUPLICATE KEY UPDATE updated_at = CURRENT_TIMESTAMP;
+-- 第一个管理员请使用 bootstrap-admin 命令或 POST /api/v1/setup 创建
 
 -- 使用生产环境数据库
 USE golang_web;
@@ -41,10 +38,7 @@ CREATE TABLE IF NOT EXISTS users (
     INDEX idx_email (email)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表';
 
--- 插入默认用户（密码: admin123）
-INSERT INTO users (username, password, email) VALUES 
-('admin', 'admin123', 'admin@example.com')
-ON DUPLICATE KEY UPDATE updated_at = CURRENT_TIMESTAMP;
+-- 第一个管理员请使用 bootstrap-admin 命令或 POST /api/v1/setup 创建
 
 -- 显示创建的表结构
 SELECT 'golang_dev 数据库表结构:' AS info;
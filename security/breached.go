@@ -0,0 +1,73 @@
+package security
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedList 本地泄露密码库，按 SHA-1 哈希匹配
+//
+// 支持两种格式:
+//   - 目录: 按哈希前 5 位分桶的文件（如 5BAA6），每行为 "剩余35位:次数"，
+//     与 Have I Been Pwned 的 k-anonymity 区间格式一致，每次只读取一个小文件
+//   - 单个文件: 每行为 "完整40位哈希[:次数]"，每次顺序扫描
+type BreachedList struct {
+	path string
+}
+
+// NewBreachedList 创建本地泄露密码库
+func NewBreachedList(path string) *BreachedList {
+	return &BreachedList{path: path}
+}
+
+// Contains 判断密码是否在泄露库中
+func (b *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return false, fmt.Errorf("读取泄露密码库失败: %v", err)
+	}
+	if info.IsDir() {
+		return b.containsInRange(hash[:5], hash[5:])
+	}
+	return scanHashes(b.path, hash)
+}
+
+// containsInRange 在前缀分桶文件中查找哈希后缀
+func (b *BreachedList) containsInRange(prefix, suffix string) (bool, error) {
+	for _, name := range []string{prefix, prefix + ".txt", strings.ToLower(prefix), strings.ToLower(prefix) + ".txt"} {
+		found, err := scanHashes(filepath.Join(b.path, name), suffix)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return found, err
+	}
+	return false, nil // 没有该前缀的文件，说明不在库中
+}
+
+// scanHashes 逐行扫描文件，比较 ":" 之前的哈希（忽略大小写）
+func scanHashes(path, hash string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, hash) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,358 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang-web/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrUnknownHashFormat 无法识别的密码哈希格式
+var ErrUnknownHashFormat = errors.New("无法识别的密码哈希格式")
+
+// Hasher 密码哈希算法
+// 生成的哈希是自描述的（包含算法和参数），可以在不同算法之间平滑迁移
+type Hasher interface {
+	// Name 算法名称
+	Name() string
+	// Hash 生成密码哈希
+	Hash(password string) (string, error)
+	// Matches 判断哈希是否由本算法生成
+	Matches(encoded string) bool
+	// Verify 验证密码与哈希是否匹配
+	Verify(password, encoded string) (bool, error)
+	// Outdated 哈希参数是否弱于当前配置
+	Outdated(encoded string) bool
+}
+
+// PasswordHasher 按配置选择首选算法，并能验证所有已支持算法生成的哈希
+type PasswordHasher struct {
+	preferred Hasher
+	all       []Hasher
+}
+
+// NewPasswordHasher 根据配置创建密码哈希器
+func NewPasswordHasher(cfg config.HashConfig) (*PasswordHasher, error) {
+	bcryptCost := cfg.BcryptCost
+	if bcryptCost == 0 {
+		bcryptCost = bcrypt.DefaultCost
+	}
+	if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt 成本因子必须在 %d 到 %d 之间", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	hashers := []Hasher{
+		&bcryptHasher{cost: bcryptCost},
+		newArgon2idHasher(cfg.Argon2id),
+		newScryptHasher(cfg.Scrypt),
+	}
+
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = "bcrypt"
+	}
+	for _, h := range hashers {
+		if h.Name() == algorithm {
+			return &PasswordHasher{preferred: h, all: hashers}, nil
+		}
+	}
+	return nil, fmt.Errorf("不支持的密码哈希算法: %s", algorithm)
+}
+
+// Hash 使用首选算法生成密码哈希
+func (p *PasswordHasher) Hash(password string) (string, error) {
+	return p.preferred.Hash(password)
+}
+
+// Verify 根据哈希格式选择对应算法验证密码
+func (p *PasswordHasher) Verify(password, encoded string) (bool, error) {
+	for _, h := range p.all {
+		if h.Matches(encoded) {
+			return h.Verify(password, encoded)
+		}
+	}
+	return false, ErrUnknownHashFormat
+}
+
+// NeedsRehash 哈希是否由非首选算法或弱于当前配置的参数生成，需要在登录时升级
+func (p *PasswordHasher) NeedsRehash(encoded string) bool {
+	if !p.preferred.Matches(encoded) {
+		return true
+	}
+	return p.preferred.Outdated(encoded)
+}
+
+var (
+	defaultHasher   *PasswordHasher
+	defaultHasherMu sync.RWMutex
+)
+
+// ConfigureHasher 根据配置设置全局密码哈希器
+func ConfigureHasher(cfg config.HashConfig) error {
+	h, err := NewPasswordHasher(cfg)
+	if err != nil {
+		return err
+	}
+	defaultHasherMu.Lock()
+	defaultHasher = h
+	defaultHasherMu.Unlock()
+	return nil
+}
+
+// DefaultHasher 获取全局密码哈希器，未配置时使用默认参数的 bcrypt
+func DefaultHasher() *PasswordHasher {
+	defaultHasherMu.RLock()
+	h := defaultHasher
+	defaultHasherMu.RUnlock()
+	if h != nil {
+		return h
+	}
+
+	h, _ = NewPasswordHasher(config.HashConfig{})
+	return h
+}
+
+// bcryptHasher bcrypt 算法，哈希格式 $2a$<cost>$...
+type bcryptHasher struct {
+	cost int
+}
+
+func (h *bcryptHasher) Name() string { return "bcrypt" }
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (h *bcryptHasher) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *bcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *bcryptHasher) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < h.cost
+}
+
+// argon2idHasher argon2id 算法，哈希格式 $argon2id$v=19$m=<KiB>,t=<次数>,p=<并行度>$<salt>$<hash>
+type argon2idHasher struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  int
+	keyLength   uint32
+}
+
+// newArgon2idHasher 创建 argon2id 哈希器，未配置的参数使用 RFC 9106 推荐的第二组参数
+func newArgon2idHasher(cfg config.Argon2idConfig) *argon2idHasher {
+	h := &argon2idHasher{
+		memory:      cfg.Memory,
+		iterations:  cfg.Iterations,
+		parallelism: cfg.Parallelism,
+		saltLength:  cfg.SaltLength,
+		keyLength:   cfg.KeyLength,
+	}
+	if h.memory == 0 {
+		h.memory = 64 * 1024
+	}
+	if h.iterations == 0 {
+		h.iterations = 3
+	}
+	if h.parallelism == 0 {
+		h.parallelism = 4
+	}
+	if h.saltLength == 0 {
+		h.saltLength = 16
+	}
+	if h.keyLength == 0 {
+		h.keyLength = 32
+	}
+	return h
+}
+
+func (h *argon2idHasher) Name() string { return "argon2id" }
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt, err := randomSalt(h.saltLength)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.iterations, h.memory, h.parallelism, h.keyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.iterations, h.parallelism, b64(salt), b64(key)), nil
+}
+
+func (h *argon2idHasher) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+// decode 解析 argon2id 哈希
+func (h *argon2idHasher) decode(encoded string) (memory, iterations uint32, parallelism uint8, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		err = ErrUnknownHashFormat
+		return
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		err = fmt.Errorf("不支持的 argon2 版本: %s", parts[2])
+		return
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return
+	}
+	if salt, err = unb64(parts[4]); err != nil {
+		return
+	}
+	key, err = unb64(parts[5])
+	return
+}
+
+func (h *argon2idHasher) Verify(password, encoded string) (bool, error) {
+	memory, iterations, parallelism, salt, key, err := h.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (h *argon2idHasher) Outdated(encoded string) bool {
+	memory, iterations, parallelism, _, key, err := h.decode(encoded)
+	if err != nil {
+		return true
+	}
+	return memory < h.memory || iterations < h.iterations || parallelism < h.parallelism || uint32(len(key)) < h.keyLength
+}
+
+// scryptHasher scrypt 算法，哈希格式 $scrypt$ln=<log2(N)>,r=<r>,p=<p>$<salt>$<hash>
+type scryptHasher struct {
+	logN       int
+	r          int
+	p          int
+	saltLength int
+	keyLength  int
+}
+
+// newScryptHasher 创建 scrypt 哈希器，未配置的参数使用 N=2^15, r=8, p=1
+func newScryptHasher(cfg config.ScryptConfig) *scryptHasher {
+	h := &scryptHasher{
+		logN:       cfg.LogN,
+		r:          cfg.R,
+		p:          cfg.P,
+		saltLength: cfg.SaltLength,
+		keyLength:  cfg.KeyLength,
+	}
+	if h.logN == 0 {
+		h.logN = 15
+	}
+	if h.r == 0 {
+		h.r = 8
+	}
+	if h.p == 0 {
+		h.p = 1
+	}
+	if h.saltLength == 0 {
+		h.saltLength = 16
+	}
+	if h.keyLength == 0 {
+		h.keyLength = 32
+	}
+	return h
+}
+
+func (h *scryptHasher) Name() string { return "scrypt" }
+
+func (h *scryptHasher) Hash(password string) (string, error) {
+	salt, err := randomSalt(h.saltLength)
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<h.logN, h.r, h.p, h.keyLength)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", h.logN, h.r, h.p, b64(salt), b64(key)), nil
+}
+
+func (h *scryptHasher) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$scrypt$")
+}
+
+// decode 解析 scrypt 哈希
+func (h *scryptHasher) decode(encoded string) (logN, r, p int, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		err = ErrUnknownHashFormat
+		return
+	}
+	if _, err = fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &logN, &r, &p); err != nil {
+		return
+	}
+	if logN <= 0 || logN > 30 {
+		err = fmt.Errorf("无效的 scrypt 参数: ln=%d", logN)
+		return
+	}
+	if salt, err = unb64(parts[3]); err != nil {
+		return
+	}
+	key, err = unb64(parts[4])
+	return
+}
+
+func (h *scryptHasher) Verify(password, encoded string) (bool, error) {
+	logN, r, p, salt, key, err := h.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual, err := scrypt.Key([]byte(password), salt, 1<<logN, r, p, len(key))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (h *scryptHasher) Outdated(encoded string) bool {
+	logN, r, p, _, key, err := h.decode(encoded)
+	if err != nil {
+		return true
+	}
+	return logN < h.logN || r < h.r || p < h.p || len(key) < h.keyLength
+}
+
+// randomSalt 生成随机盐值
+func randomSalt(n int) ([]byte, error) {
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// b64 PHC 格式使用无填充的标准 base64
+func b64(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+func unb64(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(s)
+}
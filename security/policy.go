@@ -0,0 +1,132 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang-web/config"
+)
+
+// PolicyError 密码不满足安全策略，Violations 列出所有不满足的规则
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return "密码不符合安全策略: " + strings.Join(e.Violations, "；")
+}
+
+// Policy 密码安全策略
+type Policy struct {
+	cfg      config.PasswordConfig
+	breached *BreachedList
+}
+
+// NewPolicy 根据配置创建密码安全策略
+func NewPolicy(cfg config.PasswordConfig) *Policy {
+	p := &Policy{cfg: cfg}
+	if cfg.BreachedFile != "" {
+		p.breached = NewBreachedList(cfg.BreachedFile)
+	}
+	return p
+}
+
+// HistorySize 不允许与最近多少次使用过的密码重复
+func (p *Policy) HistorySize() int {
+	return p.cfg.HistorySize
+}
+
+// minLength 最小长度，未配置时为 8
+func (p *Policy) minLength() int {
+	if p.cfg.MinLength <= 0 {
+		return 8
+	}
+	return p.cfg.MinLength
+}
+
+// bcryptMaxBytes bcrypt 能处理的最大密码字节数，超过时哈希直接失败
+const bcryptMaxBytes = 72
+
+// maxLength 最大长度（字符数），未配置时为 72
+func (p *Policy) maxLength() int {
+	if p.cfg.MaxLength <= 0 {
+		return 72
+	}
+	return p.cfg.MaxLength
+}
+
+// usesBcrypt 新密码是否使用 bcrypt 哈希
+func (p *Policy) usesBcrypt() bool {
+	return p.cfg.Hash.Algorithm == "" || p.cfg.Hash.Algorithm == "bcrypt"
+}
+
+// Validate 校验密码是否满足长度、字符类型、用户信息和泄露库规则
+// 不满足时返回 *PolicyError，泄露库读取失败时返回普通错误
+func (p *Policy) Validate(password, username, email string) error {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength() {
+		violations = append(violations, fmt.Sprintf("长度不能少于 %d 个字符", p.minLength()))
+	}
+	if length > p.maxLength() {
+		violations = append(violations, fmt.Sprintf("长度不能超过 %d 个字符", p.maxLength()))
+	}
+	// bcrypt 按字节限制长度，中文等多字节字符每个占 2-4 字节
+	if p.usesBcrypt() && len(password) > bcryptMaxBytes {
+		violations = append(violations, fmt.Sprintf("长度不能超过 %d 字节（中文等字符每个占 3 字节）", bcryptMaxBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.cfg.RequireUpper && !hasUpper {
+		violations = append(violations, "必须包含大写字母")
+	}
+	if p.cfg.RequireLower && !hasLower {
+		violations = append(violations, "必须包含小写字母")
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		violations = append(violations, "必须包含数字")
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		violations = append(violations, "必须包含特殊字符")
+	}
+
+	if p.cfg.DisallowUserInfo {
+		lower := strings.ToLower(password)
+		if username != "" && strings.Contains(lower, strings.ToLower(username)) {
+			violations = append(violations, "不能包含用户名")
+		}
+		if local, _, _ := strings.Cut(email, "@"); len(local) >= 3 && strings.Contains(lower, strings.ToLower(local)) {
+			violations = append(violations, "不能包含邮箱名")
+		}
+	}
+
+	if p.breached != nil {
+		found, err := p.breached.Contains(password)
+		if err != nil {
+			return err
+		}
+		if found {
+			violations = append(violations, "该密码已出现在泄露密码库中")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
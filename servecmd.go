@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"golang-web/config"
+	"golang-web/lifecycle"
+	"golang-web/security"
+)
+
+// runServe 启动 HTTP 服务
+// 用法: golang-web serve [-env production] [-config path]
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	opts := configFlags(fs)
+	fs.Parse(args)
+
+	// 加载配置
+	cfg := loadConfig(*opts)
+	log.Printf("应用启动，环境: %s, 端口: %s", cfg.Env, cfg.Server.Port)
+
+	// 配置热加载：JWT、会话和密码策略修改后立即生效，其余配置需要重启
+	holder := config.NewHolder(cfg)
+	holder.OnChange(func(old, new *config.Config) {
+		if err := security.ConfigureHasher(new.Password.Hash); err != nil {
+			log.Printf("密码哈希配置错误，继续使用原配置: %v", err)
+		}
+	})
+
+	// 定时任务
+	sched, err := newScheduler(cfg)
+	if err != nil {
+		log.Fatalf("注册定时任务失败: %v", err)
+	}
+
+	// 注册组件：按依赖顺序启动，关闭时按相反顺序停止，总时长不超过 server.shutdown_timeout
+	app := lifecycle.New(cfg.Server.ShutdownTimeout)
+	app.Register(databaseComponent(cfg))
+	app.Register(auditComponent(cfg), "database")
+	app.Register(configWatcherComponent(holder))
+	app.Register(sched, "database", "audit")
+	app.Register(&httpComponent{holder: holder, sched: sched}, "database", "audit", "scheduler")
+
+	// 运行直到收到退出信号，SIGHUP/SIGUSR2 触发平滑重启
+	if err := app.Run(); err != nil {
+		log.Fatalf("应用异常退出: %v", err)
+	}
+
+	log.Println("服务器已退出")
+}
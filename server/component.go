@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Name 组件名称，Server 作为 lifecycle 组件由应用管理启动和停止
+func (s *Server) Name() string {
+	return "http"
+}
+
+// Start 监听并在后台处理请求，由旧进程或 systemd（Type=notify）启动时通知其已就绪
+// 收到 SIGHUP/SIGUSR2 时启动新进程并移交监听，新进程就绪后通过 Exit 要求应用退出；启动失败时继续运行
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := s.Listen()
+	if err != nil {
+		return err
+	}
+
+	// 关闭时先单独关闭监听，Serve 使用可重复关闭的包装，Shutdown 再次关闭时不会报错
+	s.serving = &onceCloseListener{Listener: ln}
+	go func() {
+		if err := s.Serve(s.serving); err != nil && err != http.ErrServerClosed && !s.stopping.Load() {
+			s.requestExit(err)
+		}
+	}()
+	log.Printf("HTTP服务器启动在 %s://%s (pid %d)", s.Scheme(), s.Addr(), os.Getpid())
+
+	if err := notifyReady(); err != nil {
+		log.Printf("通知就绪失败: %v", err)
+	}
+
+	if len(upgradeSignals) > 0 {
+		go s.handleUpgrades(ctx, ln)
+	}
+	return nil
+}
+
+// Stop 停止接受新连接，等待进行中的请求完成或 ctx 超时
+func (s *Server) Stop(ctx context.Context) error {
+	s.stopping.Store(true)
+	if s.serving != nil {
+		s.serving.Close()
+		s.awaitNewConns(ctx)
+	}
+	return s.Shutdown(ctx)
+}
+
+// Exit 服务异常退出时收到错误，平滑重启完成时收到 nil
+func (s *Server) Exit() <-chan error {
+	return s.exit
+}
+
+// requestExit 要求应用退出，只保留第一次请求
+func (s *Server) requestExit(err error) {
+	select {
+	case s.exit <- err:
+	default:
+	}
+}
+
+// handleUpgrades 处理平滑重启信号，直到 ctx 取消或移交成功
+func (s *Server) handleUpgrades(ctx context.Context, ln net.Listener) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, upgradeSignals...)
+	defer signal.Stop(sigs)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-sigs:
+			log.Printf("收到 %v，启动新进程接管监听...", sig)
+			if err := s.upgrade(ln); err != nil {
+				log.Printf("平滑重启失败，继续运行: %v", err)
+				continue
+			}
+			log.Println("新进程已就绪，当前进程开始退出")
+			s.requestExit(nil)
+			return
+		}
+	}
+}
+
+// awaitNewConns 停止接受新连接后，等待已接受的连接读到第一个请求
+// http.Server.Shutdown 会直接关闭此后才读到请求的连接，客户端收到空响应；
+// 平滑重启时这些连接是在移交之前被当前进程接受的，必须由当前进程处理完
+// 最长等待 server.read_header_timeout（未配置时 5s）或到 ctx 超时，客户端迟迟不发送请求时放弃
+func (s *Server) awaitNewConns(ctx context.Context) {
+	timeout := s.cfg.ReadHeaderTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	for s.newConnCnt.Load() > 0 && time.Now().Before(deadline) && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// onceCloseListener 只关闭一次的监听
+type onceCloseListener struct {
+	net.Listener
+	once sync.Once
+	err  error
+}
+
+func (l *onceCloseListener) Close() error {
+	l.once.Do(func() { l.err = l.Listener.Close() })
+	return l.err
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"golang-web/config"
+)
+
+// systemd 套接字激活传入的第一个文件描述符
+const listenFDsStart = 3
+
+// listen 按 server.network 创建监听
+func listen(cfg config.ServerConfig) (net.Listener, error) {
+	switch cfg.Network {
+	case "", "tcp":
+		return net.Listen("tcp", net.JoinHostPort(cfg.Host, cfg.Port))
+	case "unix":
+		return listenUnix(cfg.Unix)
+	case "systemd":
+		return systemdListener(cfg.Systemd.Name)
+	default:
+		return nil, fmt.Errorf("不支持的监听方式: %s", cfg.Network)
+	}
+}
+
+// listenUnix 监听 Unix 域套接字，并设置文件权限和所属组
+// 套接字文件已存在且没有进程监听时（上次异常退出遗留）先删除
+func listenUnix(cfg config.UnixSocketConfig) (net.Listener, error) {
+	if err := removeStaleSocket(cfg.Path); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := uint64(0o660)
+	if cfg.Mode != "" {
+		if mode, err = strconv.ParseUint(cfg.Mode, 8, 32); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("无效的套接字权限: %s", cfg.Mode)
+		}
+	}
+	if err := os.Chmod(cfg.Path, os.FileMode(mode)); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("设置套接字权限失败: %v", err)
+	}
+
+	if cfg.Group != "" {
+		group, err := user.LookupGroup(cfg.Group)
+		if err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("查找用户组 %s 失败: %v", cfg.Group, err)
+		}
+		gid, _ := strconv.Atoi(group.Gid)
+		if err := os.Chown(cfg.Path, -1, gid); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("设置套接字所属组失败: %v", err)
+		}
+	}
+	return ln, nil
+}
+
+// removeStaleSocket 删除遗留的套接字文件，仍有进程在监听或不是套接字文件时报错
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s 已存在且不是套接字文件", path)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s 已有进程在监听", path)
+	}
+	return os.Remove(path)
+}
+
+// systemdListener 获取 systemd 套接字激活传入的监听（LISTEN_PID、LISTEN_FDS、LISTEN_FDNAMES）
+// name 为空时使用第一个；读取后清除这些环境变量，避免被子进程误用
+func systemdListener(name string) (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, errors.New("未找到 systemd 传入的监听（LISTEN_PID 不匹配，是否通过 socket 单元启动）")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, errors.New("未找到 systemd 传入的监听（LISTEN_FDS 为空）")
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	for i := 0; i < count; i++ {
+		fdName := ""
+		if i < len(names) {
+			fdName = names[i]
+		}
+		if name != "" && fdName != name {
+			continue
+		}
+
+		f := os.NewFile(uintptr(listenFDsStart+i), fdName)
+		// FileListener 会复制文件描述符，原文件可以关闭
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("使用 systemd 传入的文件描述符 %d 失败: %v", listenFDsStart+i, err)
+		}
+		return ln, nil
+	}
+	return nil, fmt.Errorf("systemd 未传入名为 %s 的监听", name)
+}
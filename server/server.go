@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"golang-web/config"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Server HTTP(S) 服务器，按 ServerConfig 设置监听地址、超时、大小限制、TLS 和 h2c
+type Server struct {
+	cfg   config.ServerConfig
+	http  *http.Server
+	certs *certReloader
+
+	// serving 正在处理请求的监听，stopping 为 true 后 Serve 返回的错误不再视为异常
+	serving  *onceCloseListener
+	stopping atomic.Bool
+	exit     chan error
+
+	// newConns 已接受但还没有读到第一个请求的连接，关闭前需要等它们的请求开始处理
+	newConns   sync.Map
+	newConnCnt atomic.Int64
+}
+
+// New 根据配置创建服务器
+func New(cfg config.ServerConfig, handler http.Handler) (*Server, error) {
+	s := &Server{cfg: cfg, exit: make(chan error, 1)}
+
+	if cfg.TLS.Enabled() {
+		tlsConfig, certs, err := buildTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		s.certs = certs
+		s.http = &http.Server{TLSConfig: tlsConfig}
+		if cfg.H2C {
+			log.Println("已启用 HTTPS，忽略 server.h2c（HTTP/2 通过 TLS 协商）")
+		}
+	} else {
+		s.http = &http.Server{}
+		if cfg.H2C {
+			handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: cfg.IdleTimeout})
+		}
+	}
+
+	s.http.Handler = handler
+	s.http.ReadTimeout = cfg.ReadTimeout
+	s.http.ReadHeaderTimeout = cfg.ReadHeaderTimeout
+	s.http.WriteTimeout = cfg.WriteTimeout
+	s.http.IdleTimeout = cfg.IdleTimeout
+	s.http.MaxHeaderBytes = cfg.MaxHeaderBytes
+	s.http.ConnState = s.trackConnState
+	return s, nil
+}
+
+// Addr 监听地址，如 :8080、127.0.0.1:8080、unix:/run/golang-web.sock
+func (s *Server) Addr() string {
+	switch s.cfg.Network {
+	case "unix":
+		return "unix:" + s.cfg.Unix.Path
+	case "systemd":
+		return "systemd:" + s.cfg.Systemd.Name
+	default:
+		return net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	}
+}
+
+// Scheme 访问协议
+func (s *Server) Scheme() string {
+	if s.cfg.TLS.Enabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// Listen 按 server.network 创建监听（TCP、Unix 域套接字或 systemd 套接字激活）
+// 由平滑重启启动的进程直接使用父进程移交的监听
+func (s *Server) Listen() (net.Listener, error) {
+	ln, err := inheritedListener()
+	if err != nil || ln != nil {
+		return ln, err
+	}
+	return listen(s.cfg)
+}
+
+// Serve 在指定监听上处理请求，直到 Shutdown 被调用（返回 http.ErrServerClosed）
+func (s *Server) Serve(ln net.Listener) error {
+	if s.certs != nil {
+		return s.http.ServeTLS(ln, "", "")
+	}
+	return s.http.Serve(ln)
+}
+
+// ListenAndServe 监听配置的地址并处理请求
+func (s *Server) ListenAndServe() error {
+	ln, err := s.Listen()
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// trackConnState 记录处于 StateNew 的连接数
+func (s *Server) trackConnState(c net.Conn, state http.ConnState) {
+	if state == http.StateNew {
+		s.newConns.Store(c, struct{}{})
+		s.newConnCnt.Add(1)
+		return
+	}
+	if _, ok := s.newConns.LoadAndDelete(c); ok {
+		s.newConnCnt.Add(-1)
+	}
+}
+
+// Shutdown 优雅关闭：停止接受新连接，等待进行中的请求完成或 ctx 超时
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.certs != nil {
+		s.certs.Close()
+	}
+	return s.http.Shutdown(ctx)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-web/config"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// clientAuthTypes server.tls.client_auth 取值对应的客户端证书认证方式
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                   tls.NoClientCert,
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// buildTLSConfig 根据配置构建服务端 TLS 配置，证书通过 certReloader 动态提供
+func buildTLSConfig(cfg config.ServerTLSConfig) (*tls.Config, *certReloader, error) {
+	clientAuth, ok := clientAuthTypes[cfg.ClientAuth]
+	if !ok {
+		return nil, nil, fmt.Errorf("不支持的客户端证书认证方式: %s", cfg.ClientAuth)
+	}
+
+	certs, err := newCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+		ClientAuth:     clientAuth,
+	}
+	if cfg.MinVersion == "1.3" {
+		tlsConfig.MinVersion = tls.VersionTLS13
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			certs.Close()
+			return nil, nil, fmt.Errorf("读取客户端CA证书失败: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			certs.Close()
+			return nil, nil, fmt.Errorf("解析客户端CA证书失败: %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+	}
+
+	return tlsConfig, certs, nil
+}
+
+// certReloader 监听证书和私钥文件，变化后重新加载
+// 新证书加载失败时继续使用原证书，证书轮换不需要重启服务
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	watcher *fsnotify.Watcher
+}
+
+// reloadDelay 证书文件变化后等待多久重新加载，证书和私钥通常先后写入
+const reloadDelay = 500 * time.Millisecond
+
+// newCertReloader 加载证书并开始监听文件变化
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("创建证书文件监听失败: %v", err)
+	}
+	// 监听所在目录而不是文件本身，兼容通过替换文件或符号链接（如 Kubernetes Secret）更新证书
+	for _, dir := range []string{filepath.Dir(certFile), filepath.Dir(keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("监听证书目录 %s 失败: %v", dir, err)
+		}
+	}
+	r.watcher = watcher
+	go r.watch()
+	return r, nil
+}
+
+// reload 重新读取证书和私钥
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("加载TLS证书失败: %v", err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// watch 处理文件变化事件，合并短时间内的多个事件
+func (r *certReloader) watch() {
+	var timer *time.Timer
+	for {
+		select {
+		case e, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if !r.relevant(e.Name) {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(reloadDelay, func() {
+				if err := r.reload(); err != nil {
+					log.Printf("重新加载TLS证书失败，继续使用原证书: %v", err)
+					return
+				}
+				log.Printf("TLS证书已重新加载: %s", r.certFile)
+			})
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("证书文件监听错误: %v", err)
+		}
+	}
+}
+
+// relevant 判断变化的文件是否为证书或私钥
+// Kubernetes 挂载的 Secret 通过替换 ..data 符号链接原子更新，同样需要重新加载
+func (r *certReloader) relevant(name string) bool {
+	base := filepath.Base(name)
+	return base == filepath.Base(r.certFile) || base == filepath.Base(r.keyFile) || strings.HasPrefix(base, "..")
+}
+
+// GetCertificate 供 tls.Config 使用，返回当前证书
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Close 停止监听
+func (r *certReloader) Close() {
+	r.watcher.Close()
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// 平滑重启时父进程传给新进程的环境变量
+const (
+	// listenFDEnv 继承的监听文件描述符
+	listenFDEnv = "GOLANG_WEB_LISTEN_FD"
+	// readyFDEnv 就绪通知管道的写端，新进程开始处理请求后写入一个字节并关闭
+	readyFDEnv = "GOLANG_WEB_READY_FD"
+)
+
+// inheritedListener 获取平滑重启时从父进程继承的监听，没有时返回 nil
+func inheritedListener() (net.Listener, error) {
+	value := os.Getenv(listenFDEnv)
+	if value == "" {
+		return nil, nil
+	}
+	os.Unsetenv(listenFDEnv)
+
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 %s: %s", listenFDEnv, value)
+	}
+	f := os.NewFile(uintptr(fd), "listener")
+	// FileListener 会复制文件描述符，原文件可以关闭
+	ln, err := net.FileListener(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("使用继承的监听失败: %v", err)
+	}
+	return ln, nil
+}
+
+// notifyReady 通知父进程（平滑重启时）和 systemd（Type=notify 时）当前进程已开始处理请求
+func notifyReady() error {
+	value := os.Getenv(readyFDEnv)
+	if value == "" {
+		return sdNotify("READY=1")
+	}
+	os.Unsetenv(readyFDEnv)
+
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("无效的 %s: %s", readyFDEnv, value)
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	defer f.Close()
+
+	// 先让 systemd 把当前进程作为服务的主进程，父进程收到就绪通知后才会退出，
+	// 否则 systemd 会认为服务已停止并结束当前进程
+	if err := sdNotify(fmt.Sprintf("MAINPID=%d\nREADY=1", os.Getpid())); err != nil {
+		return err
+	}
+	_, err = f.Write([]byte{1})
+	return err
+}
+
+// sdNotify 向 systemd 发送状态通知，不是由 systemd 以 Type=notify 启动时不做任何事
+func sdNotify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	// @ 开头表示 Linux 抽象命名空间套接字
+	if addr[0] == '@' {
+		addr = "\x00" + addr[1:]
+	}
+
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		return fmt.Errorf("连接 systemd 通知套接字失败: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("发送 systemd 通知失败: %v", err)
+	}
+	return nil
+}
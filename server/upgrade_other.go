@@ -0,0 +1,17 @@
+//go:build !unix
+
+package server
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+// upgradeSignals 当前平台不支持平滑重启
+var upgradeSignals []os.Signal
+
+// upgrade 当前平台不支持移交监听
+func (s *Server) upgrade(net.Listener) error {
+	return errors.New("当前平台不支持平滑重启")
+}
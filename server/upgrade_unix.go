@@ -0,0 +1,112 @@
+//go:build unix
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// upgradeSignals 触发平滑重启的信号
+var upgradeSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
+
+// upgrade 以相同的参数启动新版本的可执行文件，把监听的文件描述符传给它，
+// 等待它通过管道通知就绪；新进程启动失败或超时未就绪时结束新进程并返回错误
+func (s *Server) upgrade(ln net.Listener) error {
+	filer, ok := ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		return errors.New("当前监听不支持移交")
+	}
+	lnFile, err := filer.File()
+	if err != nil {
+		return fmt.Errorf("获取监听文件描述符失败: %v", err)
+	}
+	defer lnFile.Close()
+
+	ready, readyW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("创建就绪通知管道失败: %v", err)
+	}
+	defer ready.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		readyW.Close()
+		return fmt.Errorf("获取可执行文件路径失败: %v", err)
+	}
+
+	// ExtraFiles 中的文件在新进程中从 3 开始编号
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(upgradeEnv(), listenFDEnv+"=3", readyFDEnv+"=4")
+	cmd.ExtraFiles = []*os.File{lnFile, readyW}
+
+	// Unix 域套接字: 当前进程关闭监听时不能删除套接字文件，新进程还在使用
+	unixLn, isUnix := ln.(*net.UnixListener)
+	if isUnix {
+		unixLn.SetUnlinkOnClose(false)
+	}
+
+	if err := cmd.Start(); err != nil {
+		readyW.Close()
+		if isUnix {
+			unixLn.SetUnlinkOnClose(true)
+		}
+		return fmt.Errorf("启动新进程失败: %v", err)
+	}
+	readyW.Close()
+
+	// 新进程退出时管道写端关闭，Read 返回 EOF
+	result := make(chan error, 1)
+	go func() {
+		_, err := ready.Read(make([]byte, 1))
+		result <- err
+	}()
+
+	timeout := s.cfg.UpgradeTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	select {
+	case err = <-result:
+		if errors.Is(err, io.EOF) {
+			err = errors.New("新进程在就绪前退出")
+		} else if err != nil {
+			err = fmt.Errorf("新进程未能就绪: %v", err)
+		}
+	case <-time.After(timeout):
+		err = fmt.Errorf("等待新进程就绪超过 %s", timeout)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		go cmd.Wait()
+		if isUnix {
+			unixLn.SetUnlinkOnClose(true)
+		}
+		return err
+	}
+
+	cmd.Process.Release()
+	return nil
+}
+
+// upgradeEnv 当前进程的环境变量，去掉平滑重启和 systemd 套接字激活相关的变量
+func upgradeEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name := kv[:strings.IndexByte(kv+"=", '=')]
+		switch name {
+		case listenFDEnv, readyFDEnv, "LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES":
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
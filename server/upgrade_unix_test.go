@@ -0,0 +1,195 @@
+//go:build unix
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang-web/config"
+)
+
+// TestMain 平滑重启时测试二进制会被当作新版本重新执行，此时作为接管监听的子进程运行
+func TestMain(m *testing.M) {
+	if os.Getenv(listenFDEnv) != "" {
+		runHandoffChild()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// handoffMux 返回处理请求的进程ID，/slow 用于验证移交时进行中的请求会被处理完
+func handoffMux() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pid", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, os.Getpid())
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprint(w, os.Getpid())
+	})
+	return mux
+}
+
+// runHandoffChild 使用继承的监听处理请求，收到 SIGTERM 后优雅退出
+func runHandoffChild() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+
+	srv, err := New(config.ServerConfig{Host: "127.0.0.1", Port: "0"}, handoffMux())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := srv.Start(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// 测试进程异常退出时不遗留子进程
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Minute):
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv.Stop(shutdownCtx)
+	os.Exit(0)
+}
+
+// TestHandoffDropsNoRequests 持续发送请求的同时把监听移交给新进程并关闭当前服务，
+// 所有请求都必须成功，且移交后的请求由新进程处理
+func TestHandoffDropsNoRequests(t *testing.T) {
+	srv, err := New(config.ServerConfig{
+		Host:           "127.0.0.1",
+		Port:           "0",
+		UpgradeTimeout: 10 * time.Second,
+	}, handoffMux())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := srv.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ln := srv.serving.Listener
+	url := "http://" + ln.Addr().String()
+
+	// 每个请求使用新连接，与移交期间新到达的客户端一致
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{DisableKeepAlives: true},
+	}
+	var (
+		stop     atomic.Bool
+		ok       atomic.Int64
+		childPID atomic.Int64
+		mu       sync.Mutex
+		failures []string
+		wg       sync.WaitGroup
+	)
+	worker := func(path string) {
+		defer wg.Done()
+		for !stop.Load() {
+			pid, err := get(client, url+path)
+			if err != nil {
+				mu.Lock()
+				failures = append(failures, fmt.Sprintf("%s: %v", path, err))
+				mu.Unlock()
+				continue
+			}
+			ok.Add(1)
+			if pid != os.Getpid() {
+				childPID.Store(int64(pid))
+			}
+		}
+	}
+	for _, path := range []string{"/pid", "/pid", "/pid", "/pid", "/slow", "/slow"} {
+		wg.Add(1)
+		go worker(path)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if err := srv.upgrade(ln); err != nil {
+		stop.Store(true)
+		wg.Wait()
+		t.Fatalf("移交监听失败: %v", err)
+	}
+
+	// 与应用收到平滑重启完成后的处理相同：停止当前服务，等待进行中的请求完成
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	err = srv.Stop(stopCtx)
+	stopCancel()
+	if err != nil {
+		t.Errorf("关闭当前服务失败: %v", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	stop.Store(true)
+	wg.Wait()
+
+	if pid := int(childPID.Load()); pid != 0 {
+		stopChild(t, pid)
+	}
+
+	if len(failures) > 0 {
+		t.Fatalf("移交期间有 %d 个请求失败，前几个: %v", len(failures), failures[:min(len(failures), 5)])
+	}
+	if ok.Load() == 0 {
+		t.Fatal("没有成功的请求")
+	}
+	if childPID.Load() == 0 {
+		t.Fatal("移交后没有请求由新进程处理")
+	}
+	t.Logf("成功请求 %d 个，新进程 pid=%d", ok.Load(), childPID.Load())
+}
+
+// get 发送 GET 请求，状态码不是 200 时返回错误，成功时返回处理请求的进程ID
+func get(client *http.Client, url string) (int, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	return strconv.Atoi(string(body))
+}
+
+// stopChild 结束接管监听的子进程并回收
+func stopChild(t *testing.T, pid int) {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		t.Errorf("查找子进程失败: %v", err)
+		return
+	}
+	proc.Signal(syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		proc.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		proc.Kill()
+		t.Errorf("子进程 %d 未在 10s 内退出", pid)
+	}
+}
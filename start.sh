@@ -38,4 +38,4 @@ echo "环境: $GO_ENV"
 echo "应用将在 http://localhost:8080 启动"
 echo "按 Ctrl+C 停止应用"
 
-go run main.go
+go run .
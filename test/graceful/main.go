@@ -0,0 +1,46 @@
+// graceful 平滑重启测试用的最小服务，供 test/graceful_restart.sh 使用
+// 与主程序使用相同的 server 和 lifecycle 包，不依赖数据库
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"golang-web/config"
+	"golang-web/lifecycle"
+	"golang-web/server"
+)
+
+func main() {
+	port := flag.String("port", "18099", "监听端口")
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	// 返回处理请求的进程ID
+	mux.HandleFunc("/pid", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, os.Getpid())
+	})
+	// 慢请求，用于验证重启时进行中的请求会被处理完
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+		fmt.Fprint(w, os.Getpid())
+	})
+
+	srv, err := server.New(config.ServerConfig{
+		Host:           "127.0.0.1",
+		Port:           *port,
+		UpgradeTimeout: 10 * time.Second,
+	}, mux)
+	if err != nil {
+		log.Fatal(err)
+	}
+	app := lifecycle.New(10 * time.Second)
+	app.Register(srv)
+	if err := app.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
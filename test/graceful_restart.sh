@@ -0,0 +1,103 @@
+#!/usr/bin/env bash
+# 平滑重启测试：持续发送请求的同时先后发送 SIGHUP、SIGUSR2，
+# 验证没有请求失败、进行中的慢请求被处理完，且每次都由新进程接管
+#
+# 用法: ./test/graceful_restart.sh
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+
+PORT=${PORT:-18099}
+URL="http://127.0.0.1:${PORT}"
+WORKDIR=$(mktemp -d)
+BIN="$WORKDIR/graceful"
+STOP="$WORKDIR/stop"
+
+cleanup() {
+	touch "$STOP"
+	if [ -n "${CURRENT:-}" ]; then
+		kill -TERM "$CURRENT" 2>/dev/null || true
+	fi
+	rm -rf "$WORKDIR"
+}
+trap cleanup EXIT
+
+go build -o "$BIN" ./test/graceful
+"$BIN" -port "$PORT" >"$WORKDIR/server.log" 2>&1 &
+FIRST=$!
+
+for _ in $(seq 1 50); do
+	curl -sf "$URL/pid" >/dev/null 2>&1 && break
+	sleep 0.1
+done
+CURRENT=$(curl -sf "$URL/pid")
+echo "服务已启动，pid=$CURRENT"
+
+# 并发请求，失败时记录
+worker() {
+	local path=$1 id=$2
+	while [ ! -e "$STOP" ]; do
+		local rc=0
+		curl -sf --max-time 10 -o /dev/null "$URL$path" || rc=$?
+		if [ "$rc" -eq 0 ]; then
+			echo ok >>"$WORKDIR/ok.$id"
+		else
+			echo "$(date +%T.%N) $path curl=$rc" >>"$WORKDIR/failed.$id"
+		fi
+	done
+}
+WORKERS=()
+for i in 1 2 3 4; do
+	worker /pid "$i" &
+	WORKERS+=($!)
+done
+for i in 5 6; do
+	worker /slow "$i" &
+	WORKERS+=($!)
+done
+
+# 等待 pid 变化，确认新进程接管
+wait_for_new_pid() {
+	local old=$1
+	for _ in $(seq 1 100); do
+		local pid
+		pid=$(curl -sf "$URL/pid" || true)
+		if [ -n "$pid" ] && [ "$pid" != "$old" ] && ! kill -0 "$old" 2>/dev/null; then
+			echo "$pid"
+			return 0
+		fi
+		sleep 0.1
+	done
+	echo "等待新进程接管超时（旧进程 $old）" >&2
+	return 1
+}
+
+sleep 1
+kill -HUP "$CURRENT"
+CURRENT=$(wait_for_new_pid "$CURRENT")
+echo "SIGHUP 后由 pid=$CURRENT 接管"
+
+sleep 1
+kill -USR2 "$CURRENT"
+CURRENT=$(wait_for_new_pid "$CURRENT")
+echo "SIGUSR2 后由 pid=$CURRENT 接管"
+
+sleep 1
+touch "$STOP"
+wait "${WORKERS[@]}"
+
+count() {
+	find "$WORKDIR" -name "$1" -exec cat {} + | wc -l
+}
+OK=$(count 'ok.*')
+FAILED=$(count 'failed.*')
+echo "成功请求: $OK，失败请求: $FAILED"
+
+if [ "$FAILED" -ne 0 ] || [ "$OK" -eq 0 ]; then
+	echo "测试失败，失败的请求:"
+	cat "$WORKDIR"/failed.* 2>/dev/null || true
+	echo "服务日志:"
+	cat "$WORKDIR/server.log"
+	exit 1
+fi
+echo "测试通过"
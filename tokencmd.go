@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-web/database"
+	"golang-web/models"
+	"golang-web/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenUsage token 命令的用法
+const tokenUsage = `用法:
+  golang-web token issue [-ttl 24h] [-device cli] [-audience aud1,aud2] [-scope s1,s2] <用户名>
+                                                            为用户签发令牌（创建登录会话，可在会话列表中注销）
+  golang-web token inspect <令牌|->                          校验令牌并输出声明，- 表示从标准输入读取
+  golang-web token introspect <令牌|->                       按内省接口的格式输出令牌状态，包括会话是否已注销
+`
+
+// runToken 令牌工具子命令
+func runToken(args []string) {
+	if len(args) == 0 {
+		subcommandUsage(tokenUsage)
+	}
+
+	switch args[0] {
+	case "issue":
+		runTokenIssue(args[1:])
+	case "inspect":
+		runTokenInspect(args[1:])
+	case "introspect":
+		runTokenIntrospect(args[1:])
+	default:
+		subcommandUsage(tokenUsage)
+	}
+}
+
+// runTokenIssue 为用户签发令牌，令牌输出到标准输出，便于在脚本中使用
+func runTokenIssue(args []string) {
+	fs := flag.NewFlagSet("token issue", flag.ExitOnError)
+	opts := configFlags(fs)
+	ttl := fs.Duration("ttl", 0, "有效期，默认使用 jwt.expire")
+	device := fs.String("device", "cli", "会话的设备名称")
+	audience := fs.String("audience", "", "令牌受众，多个用逗号分隔，默认使用 jwt.audience")
+	scope := fs.String("scope", "", "授权范围，多个用逗号分隔，如 profile:read,sessions，默认不限制")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		subcommandUsage(tokenUsage)
+	}
+
+	cfg := openUserDB(*opts)
+	defer database.CloseDB()
+
+	ctx := context.Background()
+	user := mustGetUser(ctx, fs.Arg(0))
+	if !user.IsActive() {
+		log.Fatalf("用户状态为 %s，不能签发令牌", user.Status)
+	}
+
+	expiresAt := utils.TokenExpiry(cfg)
+	if *ttl > 0 {
+		expiresAt = utils.CapExpiry(time.Now().Add(*ttl), time.Now(), cfg)
+	}
+	session, err := models.CreateSession(ctx, user.ID, *device, "", "golang-web token issue", expiresAt)
+	if err != nil {
+		log.Fatalf("创建会话失败: %v", err)
+	}
+	tokenOpts := []utils.TokenOption{utils.WithSessionID(session.ID), utils.WithExpiry(expiresAt)}
+	if audiences := splitList(*audience); len(audiences) > 0 {
+		for _, aud := range audiences {
+			if !slices.Contains(cfg.KnownAudiences(), aud) {
+				fmt.Fprintf(os.Stderr, "警告: 受众 %s 不在 jwt.audience 和 clients 配置中，令牌无法通过本服务校验\n", aud)
+			}
+		}
+		tokenOpts = append(tokenOpts, utils.WithAudience(audiences...))
+	}
+	if scopes := splitList(*scope); len(scopes) > 0 {
+		tokenOpts = append(tokenOpts, utils.WithScopes(scopes...))
+	}
+	token, err := utils.GenerateToken(user.ID, user.Username, cfg, tokenOpts...)
+	if err != nil {
+		log.Fatalf("生成令牌失败: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "已为 %s 签发令牌，会话 %s，过期时间 %s\n",
+		user.Username, session.ID, expiresAt.Format("2006-01-02 15:04:05"))
+	fmt.Println(token)
+}
+
+// runTokenInspect 用当前配置的密钥、签发者和已知受众校验令牌并输出声明，令牌无效时仍输出声明并以状态码 1 退出
+func runTokenInspect(args []string) {
+	fs := flag.NewFlagSet("token inspect", flag.ExitOnError)
+	opts := configFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		subcommandUsage(tokenUsage)
+	}
+
+	tokenString := tokenArg(fs.Arg(0))
+	cfg := loadConfig(*opts)
+	claims, validErr := utils.ValidateTokenFor(tokenString, cfg, cfg.KnownAudiences()...)
+	if validErr != nil {
+		// 签名或有效期校验失败时仍解析声明，便于排查
+		claims = &utils.Claims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+			log.Fatalf("无法解析令牌: %v", err)
+		}
+	}
+
+	out, err := json.MarshalIndent(claims, "", "  ")
+	if err != nil {
+		log.Fatalf("输出声明失败: %v", err)
+	}
+	fmt.Println(string(out))
+
+	if validErr != nil {
+		fmt.Fprintf(os.Stderr, "令牌无效: %v\n", validErr)
+		os.Exit(1)
+	}
+	if claims.ExpiresAt != nil {
+		fmt.Fprintf(os.Stderr, "令牌有效，过期时间 %s\n", claims.ExpiresAt.Time.Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Fprintln(os.Stderr, "令牌有效，未设置过期时间")
+	}
+}
+
+// runTokenIntrospect 与 POST /api/v1/token/introspect 相同，输出令牌状态，令牌无效或已注销时以状态码 1 退出
+func runTokenIntrospect(args []string) {
+	fs := flag.NewFlagSet("token introspect", flag.ExitOnError)
+	opts := configFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		subcommandUsage(tokenUsage)
+	}
+
+	tokenString := tokenArg(fs.Arg(0))
+	cfg := openUserDB(*opts)
+	defer database.CloseDB()
+
+	result, err := models.IntrospectToken(context.Background(), tokenString, cfg, cfg.KnownAudiences())
+	if err != nil {
+		log.Fatalf("校验会话失败: %v", err)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatalf("输出结果失败: %v", err)
+	}
+	fmt.Println(string(out))
+
+	if !result.Active {
+		database.CloseDB()
+		os.Exit(1)
+	}
+}
+
+// tokenArg 读取命令行中的令牌，- 表示从标准输入读取，允许带 Bearer 前缀
+func tokenArg(arg string) string {
+	if arg == "-" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("读取令牌失败: %v", err)
+		}
+		arg = line
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(arg), "Bearer "))
+}
+
+// splitList 按逗号拆分参数，去掉空白和空项
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"golang-web/config"
+	"golang-web/database"
+	"golang-web/models"
+	"golang-web/security"
+)
+
+// userUsage user 命令的用法
+const userUsage = `用法:
+  golang-web user create -username <用户名> [-email <邮箱>] [-password <密码>] [-role user|admin]
+  golang-web user list [-offset 0] [-limit 50]
+  golang-web user disable <用户名>
+  golang-web user enable <用户名>
+  golang-web user reset-password [-password <密码>] <用户名>
+
+未指定密码时随机生成并只打印一次
+`
+
+// runUser 用户管理子命令
+func runUser(args []string) {
+	if len(args) == 0 {
+		subcommandUsage(userUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		runUserCreate(args[1:])
+	case "list":
+		runUserList(args[1:])
+	case "disable":
+		runUserDisable(args[1:])
+	case "enable":
+		runUserEnable(args[1:])
+	case "reset-password":
+		runUserResetPassword(args[1:])
+	default:
+		subcommandUsage(userUsage)
+	}
+}
+
+// runUserCreate 创建用户
+func runUserCreate(args []string) {
+	fs := flag.NewFlagSet("user create", flag.ExitOnError)
+	opts := configFlags(fs)
+	username := fs.String("username", "", "用户名（必填）")
+	email := fs.String("email", "", "邮箱")
+	password := fs.String("password", "", "密码，为空时随机生成")
+	role := fs.String("role", models.RoleUser, "角色: user / admin")
+	fs.Parse(args)
+
+	if *username == "" || (*role != models.RoleUser && *role != models.RoleAdmin) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := openUserDB(*opts)
+	defer database.CloseDB()
+
+	pw, generated := passwordOrGenerate(*password)
+	if err := security.NewPolicy(cfg.Password).Validate(pw, *username, *email); err != nil {
+		log.Fatalf("密码不符合要求: %v", err)
+	}
+
+	ctx := context.Background()
+	user, err := models.CreateUser(ctx, &models.RegisterRequest{Username: *username, Password: pw, Email: *email})
+	if err != nil {
+		log.Fatalf("创建用户失败: %v", err)
+	}
+	if *role == models.RoleAdmin {
+		if err := models.SetUserRole(ctx, user.ID, models.RoleAdmin, 0); err != nil {
+			log.Fatalf("设置管理员角色失败: %v", err)
+		}
+	}
+
+	fmt.Printf("用户已创建: id=%d, username=%s, role=%s\n", user.ID, user.Username, *role)
+	printGeneratedPassword(pw, generated)
+}
+
+// runUserList 列出用户（不含已删除用户）
+func runUserList(args []string) {
+	fs := flag.NewFlagSet("user list", flag.ExitOnError)
+	opts := configFlags(fs)
+	offset := fs.Int("offset", 0, "跳过的用户数")
+	limit := fs.Int("limit", 50, "最多列出的用户数")
+	fs.Parse(args)
+
+	openUserDB(*opts)
+	defer database.CloseDB()
+
+	users, err := models.ListUsers(context.Background(), *offset, *limit)
+	if err != nil {
+		log.Fatalf("获取用户列表失败: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\t用户名\t邮箱\t角色\t状态\t最后登录")
+	for _, u := range users {
+		lastLogin := "-"
+		if u.LastLoginAt != nil {
+			lastLogin = u.LastLoginAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", u.ID, u.Username, u.Email, u.Role, u.Status, lastLogin)
+	}
+	w.Flush()
+}
+
+// runUserDisable 禁用用户并注销其所有会话
+func runUserDisable(args []string) {
+	fs := flag.NewFlagSet("user disable", flag.ExitOnError)
+	opts := configFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		subcommandUsage(userUsage)
+	}
+
+	openUserDB(*opts)
+	defer database.CloseDB()
+
+	ctx := context.Background()
+	user := mustGetUser(ctx, fs.Arg(0))
+	if err := models.SetUserStatus(ctx, user.ID, models.UserStatusDisabled, 0); err != nil {
+		log.Fatalf("禁用用户失败: %v", err)
+	}
+	if err := models.RevokeUserSessions(ctx, user.ID, ""); err != nil {
+		log.Fatalf("注销用户会话失败: %v", err)
+	}
+	fmt.Printf("用户已禁用: id=%d, username=%s\n", user.ID, user.Username)
+}
+
+// runUserEnable 重新启用被禁用的用户
+func runUserEnable(args []string) {
+	fs := flag.NewFlagSet("user enable", flag.ExitOnError)
+	opts := configFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		subcommandUsage(userUsage)
+	}
+
+	openUserDB(*opts)
+	defer database.CloseDB()
+
+	ctx := context.Background()
+	user := mustGetUser(ctx, fs.Arg(0))
+	if err := models.SetUserStatus(ctx, user.ID, models.UserStatusActive, 0); err != nil {
+		log.Fatalf("启用用户失败: %v", err)
+	}
+	fmt.Printf("用户已启用: id=%d, username=%s\n", user.ID, user.Username)
+}
+
+// runUserResetPassword 重置用户密码并注销其所有会话
+func runUserResetPassword(args []string) {
+	fs := flag.NewFlagSet("user reset-password", flag.ExitOnError)
+	opts := configFlags(fs)
+	password := fs.String("password", "", "新密码，为空时随机生成")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		subcommandUsage(userUsage)
+	}
+
+	cfg := openUserDB(*opts)
+	defer database.CloseDB()
+
+	ctx := context.Background()
+	user := mustGetUser(ctx, fs.Arg(0))
+
+	pw, generated := passwordOrGenerate(*password)
+	policy := security.NewPolicy(cfg.Password)
+	if err := policy.Validate(pw, user.Username, user.Email); err != nil {
+		log.Fatalf("密码不符合要求: %v", err)
+	}
+	used, err := models.PasswordUsedRecently(ctx, user, pw, policy.HistorySize())
+	if err != nil {
+		log.Fatalf("校验密码历史失败: %v", err)
+	}
+	if used {
+		log.Fatal("密码不符合要求: 不能与最近使用过的密码相同")
+	}
+
+	if err := models.UpdatePassword(ctx, user.ID, pw, 0); err != nil {
+		log.Fatalf("重置密码失败: %v", err)
+	}
+	if err := models.RevokeUserSessions(ctx, user.ID, ""); err != nil {
+		log.Fatalf("注销用户会话失败: %v", err)
+	}
+
+	fmt.Printf("密码已重置: id=%d, username=%s\n", user.ID, user.Username)
+	printGeneratedPassword(pw, generated)
+}
+
+// openUserDB 加载配置并连接数据库，不执行迁移（由 migrate up 或启动服务执行）
+func openUserDB(opts config.Options) *config.Config {
+	cfg := loadConfig(opts)
+	if err := database.Open(cfg); err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	if pending, err := database.PendingMigrations(context.Background()); err == nil && pending > 0 {
+		log.Printf("警告: 有 %d 个未执行的数据库迁移，请执行 migrate up", pending)
+	}
+	return cfg
+}
+
+// mustGetUser 按用户名获取用户（读主库），不存在时终止程序
+func mustGetUser(ctx context.Context, username string) *models.User {
+	user, err := models.GetUserByUsername(database.UsePrimary(ctx), username)
+	if err != nil {
+		log.Fatalf("获取用户失败: %v", err)
+	}
+	if user == nil {
+		log.Fatalf("用户不存在: %s", username)
+	}
+	return user
+}
+
+// passwordOrGenerate 未指定密码时随机生成
+func passwordOrGenerate(password string) (string, bool) {
+	if password != "" {
+		return password, false
+	}
+	p, err := models.GeneratePassword(20)
+	if err != nil {
+		log.Fatalf("生成随机密码失败: %v", err)
+	}
+	return p, true
+}
+
+// printGeneratedPassword 打印随机生成的密码
+func printGeneratedPassword(password string, generated bool) {
+	if generated {
+		fmt.Printf("随机生成的密码（只显示这一次，请妥善保存）: %s\n", password)
+	}
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 
 	"golang-web/config"
@@ -9,30 +12,196 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+var (
+	// ErrTokenTooOld 令牌签发时间早于 jwt.max_age 允许的范围
+	ErrTokenTooOld = errors.New("令牌签发时间过早，请重新登录")
+	// ErrSessionLifetimeExceeded 距首次登录已超过 session.absolute_lifetime，刷新也不能延长
+	ErrSessionLifetimeExceeded = errors.New("登录已超过最长有效期，请重新登录")
+)
+
 // Claims JWT声明结构
 type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	SessionID string `json:"sid,omitempty"` // 登录会话ID，用于会话管理和注销
+
+	// 首次登录（输入密码）的时间，刷新令牌时保持不变，用于限制会话的最长有效期
+	OrigIssuedAt *jwt.NumericDate `json:"orig_iat,omitempty"`
+	// 最近一次输入密码的时间（登录或重新验证），用于要求近期认证的敏感操作
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+
+	// 应用自定义声明，生成令牌时通过 TokenOption 设置
+	Tenant string                 `json:"tenant,omitempty"` // 租户
+	Roles  []string               `json:"roles,omitempty"`  // 角色
+	Scope  string                 `json:"scope,omitempty"`  // 授权范围，空格分隔（RFC 8693），为空表示不限制
+	Extra  map[string]interface{} `json:"ext,omitempty"`    // 其他自定义声明，放在 ext 下避免与标准声明冲突
+
 	jwt.RegisteredClaims
 }
 
+// LoginTime 首次登录的时间，此前签发的令牌没有 orig_iat 时使用 iat
+func (c *Claims) LoginTime() time.Time {
+	if c.OrigIssuedAt != nil {
+		return c.OrigIssuedAt.Time
+	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.Time
+	}
+	return time.Time{}
+}
+
+// AuthenticatedAt 最近一次输入密码的时间，此前签发的令牌没有 auth_time 时使用首次登录时间
+func (c *Claims) AuthenticatedAt() time.Time {
+	if c.AuthTime != nil {
+		return c.AuthTime.Time
+	}
+	return c.LoginTime()
+}
+
+// Scopes 令牌的授权范围列表，未限制时返回 nil
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// HasScope 令牌是否具有指定的授权范围，未限制授权范围的令牌具有全部权限
+func (c *Claims) HasScope(scope string) bool {
+	if c.Scope == "" {
+		return true
+	}
+	for _, s := range c.Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenOption 生成令牌时的可选参数
+type TokenOption func(*Claims)
+
+// WithSessionID 将令牌关联到登录会话
+func WithSessionID(sessionID string) TokenOption {
+	return func(c *Claims) {
+		c.SessionID = sessionID
+	}
+}
+
+// WithTenant 设置令牌所属租户
+func WithTenant(tenant string) TokenOption {
+	return func(c *Claims) {
+		c.Tenant = tenant
+	}
+}
+
+// WithRoles 设置令牌携带的角色
+func WithRoles(roles ...string) TokenOption {
+	return func(c *Claims) {
+		c.Roles = roles
+	}
+}
+
+// WithScopes 限制令牌的授权范围，只能访问要求这些范围的接口（见 middleware.RequireScope）
+func WithScopes(scopes ...string) TokenOption {
+	return func(c *Claims) {
+		c.Scope = strings.Join(scopes, " ")
+	}
+}
+
+// WithClaim 添加自定义声明，值需要可以编码为 JSON
+func WithClaim(key string, value interface{}) TokenOption {
+	return func(c *Claims) {
+		if c.Extra == nil {
+			c.Extra = make(map[string]interface{})
+		}
+		c.Extra[key] = value
+	}
+}
+
+// WithAuthTime 设置最近一次输入密码的时间，默认为签发时间
+func WithAuthTime(t time.Time) TokenOption {
+	return func(c *Claims) {
+		c.AuthTime = jwt.NewNumericDate(t)
+	}
+}
+
+// WithClaimsFrom 沿用原令牌的会话、认证时间、受众和自定义声明，用于刷新或重新签发令牌
+func WithClaimsFrom(old *Claims) TokenOption {
+	return func(c *Claims) {
+		c.SessionID = old.SessionID
+		c.OrigIssuedAt = jwt.NewNumericDate(old.LoginTime())
+		c.AuthTime = jwt.NewNumericDate(old.AuthenticatedAt())
+		c.Audience = old.Audience
+		c.Tenant = old.Tenant
+		c.Roles = old.Roles
+		c.Scope = old.Scope
+		c.Extra = old.Extra
+	}
+}
+
+// WithAudience 指定令牌的受众，默认为 jwt.audience
+// 面向下游服务的令牌不能用于访问本服务接口，只能由对应的客户端通过内省接口校验
+func WithAudience(audience ...string) TokenOption {
+	return func(c *Claims) {
+		c.Audience = audience
+	}
+}
+
+// WithExpiry 指定令牌的过期时间，默认按 jwt.expire 计算
+func WithExpiry(expiresAt time.Time) TokenOption {
+	return func(c *Claims) {
+		c.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	}
+}
+
+// TokenExpiry 按配置计算从现在开始登录的令牌过期时间，不超过 session.absolute_lifetime
+func TokenExpiry(cfg *config.Config) time.Time {
+	now := time.Now()
+	return CapExpiry(now.Add(time.Duration(cfg.JWT.Expire)*time.Hour), now, cfg)
+}
+
+// CapExpiry 将过期时间限制在首次登录时间加 session.absolute_lifetime 之内
+func CapExpiry(expiresAt, loginTime time.Time, cfg *config.Config) time.Time {
+	if cfg.Session.AbsoluteLifetime <= 0 {
+		return expiresAt
+	}
+	if limit := loginTime.Add(cfg.Session.AbsoluteLifetime); expiresAt.After(limit) {
+		return limit
+	}
+	return expiresAt
+}
+
 // GenerateToken 生成JWT令牌
-func GenerateToken(userID int, username string, cfg *config.Config) (string, error) {
-	// 设置过期时间
-	expirationTime := time.Now().Add(time.Duration(cfg.JWT.Expire) * time.Hour)
+// 过期时间不会超过首次登录时间（orig_iat）加 session.absolute_lifetime
+func GenerateToken(userID int, username string, cfg *config.Config, opts ...TokenOption) (string, error) {
+	now := time.Now()
+	expirationTime := now.Add(time.Duration(cfg.JWT.Expire) * time.Hour)
+
+	// 令牌唯一ID
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
 
 	// 创建声明
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:       userID,
+		Username:     username,
+		OrigIssuedAt: jwt.NewNumericDate(now),
+		AuthTime:     jwt.NewNumericDate(now),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "golang-web",
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    cfg.JWT.Issuer,
 			Subject:   username,
+			Audience:  jwt.ClaimStrings{cfg.JWT.Audience},
+			ID:        jti,
 		},
 	}
+	for _, opt := range opts {
+		opt(claims)
+	}
+	claims.ExpiresAt = jwt.NewNumericDate(CapExpiry(claims.ExpiresAt.Time, claims.LoginTime(), cfg))
 
 	// 创建令牌
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -46,8 +215,18 @@ func GenerateToken(userID int, username string, cfg *config.Config) (string, err
 	return tokenString, nil
 }
 
-// ValidateToken 验证JWT令牌
+// ValidateToken 验证用于访问本服务接口的JWT令牌，受众必须包含 jwt.audience
 func ValidateToken(tokenString string, cfg *config.Config) (*Claims, error) {
+	return ValidateTokenFor(tokenString, cfg, cfg.JWT.Audience)
+}
+
+// ValidateTokenFor 验证JWT令牌，受众包含 audiences 之一即可
+// 同时校验签发者、有效期（允许 jwt.leeway 的时钟偏差）和 jwt.max_age
+func ValidateTokenFor(tokenString string, cfg *config.Config, audiences ...string) (*Claims, error) {
+	if len(audiences) == 0 {
+		return nil, errors.New("未指定令牌受众")
+	}
+
 	// 解析令牌
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// 验证签名方法
@@ -55,7 +234,13 @@ func ValidateToken(tokenString string, cfg *config.Config) (*Claims, error) {
 			return nil, errors.New("无效的签名方法")
 		}
 		return []byte(cfg.JWT.SecretKey), nil
-	})
+	},
+		jwt.WithIssuer(cfg.JWT.Issuer),
+		jwt.WithAudience(audiences...),
+		jwt.WithLeeway(cfg.JWT.Leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -72,17 +257,51 @@ func ValidateToken(tokenString string, cfg *config.Config) (*Claims, error) {
 		return nil, errors.New("无法提取声明")
 	}
 
+	// 限制令牌最长使用时间，即使 exp 设置得很远
+	if cfg.JWT.MaxAge > 0 {
+		if claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > cfg.JWT.MaxAge+cfg.JWT.Leeway {
+			return nil, ErrTokenTooOld
+		}
+	}
+
+	// 缩短 session.absolute_lifetime 后，已签发的令牌同样受限
+	if cfg.Session.AbsoluteLifetime > 0 && time.Since(claims.LoginTime()) > cfg.Session.AbsoluteLifetime+cfg.JWT.Leeway {
+		return nil, ErrSessionLifetimeExceeded
+	}
+
 	return claims, nil
 }
 
-// RefreshToken 刷新JWT令牌
-func RefreshToken(tokenString string, cfg *config.Config) (string, error) {
+// RefreshToken 刷新JWT令牌，返回新令牌和过期时间
+// 新令牌的过期时间不超过首次登录时间加 session.absolute_lifetime，已到达该时间时返回 ErrSessionLifetimeExceeded
+func RefreshToken(tokenString string, cfg *config.Config) (string, time.Time, error) {
 	// 验证当前令牌
 	claims, err := ValidateToken(tokenString, cfg)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
+	}
+
+	// 超过从登录起的最长有效期时要求重新登录
+	// 只按 orig_iat 判断：调小 jwt.expire 后新令牌可能比旧令牌先过期，仍允许刷新
+	now := time.Now()
+	if lifetime := cfg.Session.AbsoluteLifetime; lifetime > 0 && !now.Before(claims.LoginTime().Add(lifetime)) {
+		return "", time.Time{}, ErrSessionLifetimeExceeded
 	}
+	expiresAt := CapExpiry(now.Add(time.Duration(cfg.JWT.Expire)*time.Hour), claims.LoginTime(), cfg)
 
-	// 生成新令牌
-	return GenerateToken(claims.UserID, claims.Username, cfg)
+	// 生成新令牌，沿用原会话、受众和自定义声明，授权范围不会扩大
+	token, err := GenerateToken(claims.UserID, claims.Username, cfg, WithClaimsFrom(claims), WithExpiry(expiresAt))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// newTokenID 生成随机令牌ID（jti）
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
@@ -0,0 +1,17 @@
+package utils
+
+// Truncate 按字节截断字符串，避免超出字段长度（不截断多字节字符）
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !isRuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// isRuneStart 判断字节是否为 UTF-8 字符的起始字节
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,48 @@
+package utils
+
+import "strings"
+
+// DeviceFromUserAgent 从 User-Agent 粗略识别设备描述，如 "Chrome on Windows"
+func DeviceFromUserAgent(ua string) string {
+	if ua == "" {
+		return "未知设备"
+	}
+
+	platform := "未知系统"
+	switch {
+	case strings.Contains(ua, "iPhone"):
+		platform = "iPhone"
+	case strings.Contains(ua, "iPad"):
+		platform = "iPad"
+	case strings.Contains(ua, "Android"):
+		platform = "Android"
+	case strings.Contains(ua, "Windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "Linux"):
+		platform = "Linux"
+	}
+
+	// 顺序很重要：Edge/Opera 的 UA 中也包含 Chrome，Chrome 的 UA 中也包含 Safari
+	client := ""
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		client = "Edge"
+	case strings.Contains(ua, "OPR/"):
+		client = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		client = "Firefox"
+	case strings.Contains(ua, "Chrome/"):
+		client = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		client = "Safari"
+	case strings.Contains(ua, "curl/"):
+		client = "curl"
+	}
+
+	if client == "" {
+		return platform
+	}
+	return client + " on " + platform
+}